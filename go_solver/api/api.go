@@ -0,0 +1,191 @@
+// Package api defines the JSON requests and responses for asking the solvers
+// about a position, shared by the HTTP service and the WebAssembly build.
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/exhaustive"
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// Position describes a game either as deal text (the FromString format) or
+// as the rows the frontend holds, e.g. [["4H","KD"],["8S"],...]
+type Position struct {
+	Deal string     `json:"deal,omitempty"`
+	Rows [][]string `json:"rows,omitempty"`
+	// Foundations gives the top value on each suit's foundation, e.g.
+	// {"H": 2}; when omitted, cards missing from the rows are taken to be up
+	Foundations map[string]int `json:"foundations,omitempty"`
+	// Base is the rank the foundations start from, in variants where the
+	// first foundation card chooses it
+	Base int `json:"base,omitempty"`
+	// Variant names the rules, e.g. "beleaguered-castle"; the default is
+	// Streets and Alleys unless the deal text has a "variant:" line
+	Variant string `json:"variant,omitempty"`
+}
+
+// Game parses the position into a game state, checking that it holds each
+// card exactly once (see streets.Lenient)
+func (p Position) Game() (streets.StreetsGame, error) {
+	text := p.Deal
+	if text == "" {
+		if len(p.Rows) > streets.MaxRows {
+			return streets.StreetsGame{}, fmt.Errorf("too many rows: %d", len(p.Rows))
+		}
+		rows := make([]string, len(p.Rows))
+		for i, row := range p.Rows {
+			rows[i] = strings.Join(row, " ")
+		}
+		text = strings.Join(rows, "\n")
+	}
+
+	if p.Foundations != nil {
+		var tops []string
+		for suit, top := range p.Foundations {
+			if top < 0 || top > 13 {
+				return streets.StreetsGame{}, fmt.Errorf("invalid foundation value for %s: %d", suit, top)
+			}
+			if top > 0 {
+				tops = append(tops, streets.Card{Value: top, Suit: suit}.String())
+			}
+		}
+		text += "\nfoundations: " + strings.Join(tops, " ")
+	}
+	if p.Base != 0 {
+		if p.Base < 1 || p.Base > 13 {
+			return streets.StreetsGame{}, fmt.Errorf("invalid base rank: %d", p.Base)
+		}
+		text += "\nbase: " + streets.RankString(p.Base)
+	}
+
+	var game streets.StreetsGame
+	if p.Variant != "" {
+		variant, err := streets.ParseVariant(p.Variant)
+		if err != nil {
+			return game, err
+		}
+		game.Variant = variant
+	}
+	return game, game.FromString(text)
+}
+
+// FromGame returns the rows and foundations of game in Position form
+func FromGame(game streets.StreetsGame) Position {
+	rows := make([][]string, game.RowCount())
+	for row := range rows {
+		rows[row] = []string{}
+		for col := 0; col < 19; col++ {
+			if card := game.Rows[row][col]; (card != streets.Card{}) {
+				rows[row] = append(rows[row], card.String())
+			}
+		}
+	}
+	return Position{Rows: rows, Foundations: game.FoundationTops(), Base: game.Base, Variant: game.Variant.String()}
+}
+
+// LegalMoves returns the legal moves in game as [from,to] pairs
+func LegalMoves(game streets.StreetsGame) [][2]int {
+	return movePairs(game.LegalMoves())
+}
+
+// Options are the search limits used to answer a request
+type Options struct {
+	MaxStates  int              // Positions the exhaustive solver may visit
+	Iterations int              // MCTS iterations for a hint when the position isn't solved, 0 for the default
+	Autoplay   streets.Autoplay // Foundation moves to play automatically
+	// Evaluator, if set, scores where a hint search's rollouts end, e.g. with
+	// weights written by the solver's train command; the zero value scores
+	// them by the cards up
+	Evaluator streets.Evaluator
+}
+
+// SolveResponse is the answer to a solve request
+type SolveResponse struct {
+	Status string   `json:"status"` // "winnable", "unwinnable" or "unknown"
+	Moves  [][2]int `json:"moves"`  // Winning line as [from,to] pairs, with -1 for the foundation
+	States int      `json:"states"` // Positions searched
+}
+
+// HintResponse is the answer to a hint request
+type HintResponse struct {
+	Status string  `json:"status"`           // Exhaustive result within the hint budget
+	Move   *[2]int `json:"move"`             // Suggested [from,to], null when there are no moves
+	Reward float64 `json:"reward,omitempty"` // MCTS estimate when no winning line was found
+}
+
+// HealthResponse is the answer to a health request
+type HealthResponse struct {
+	Health   float64            `json:"health"`   // Position evaluator's score, 0 hopeless to 1 won
+	Features map[string]float64 `json:"features"` // The measurements it was based on, by name
+}
+
+// Health scores game with evaluator, e.g. streets.DefaultEvaluator()
+func Health(game streets.StreetsGame, evaluator streets.Evaluator) HealthResponse {
+	features := game.Features()
+	response := HealthResponse{Health: evaluator.Evaluate(&game), Features: make(map[string]float64)}
+	for i, name := range streets.FeatureNames {
+		response.Features[name] = features[i]
+	}
+	return response
+}
+
+// Solve runs the exhaustive solver on game
+func Solve(game streets.StreetsGame, opts Options) SolveResponse {
+	result := exhaustive.Solve(game, opts.MaxStates, opts.Autoplay)
+	return SolveResponse{
+		Status: result.Status.String(),
+		Moves:  movePairs(result.Moves),
+		States: result.States,
+	}
+}
+
+// Hint suggests the next move: the first move of a winning line if the
+// exhaustive solver finds one within budget, otherwise the MCTS best move,
+// which still helps get the most cards up in a lost position
+func Hint(game streets.StreetsGame, opts Options, rng *rand.Rand) HintResponse {
+	result := exhaustive.Solve(game, opts.MaxStates, opts.Autoplay)
+	response := HintResponse{Status: result.Status.String()}
+
+	switch {
+	case result.Status == exhaustive.Winnable && len(result.Moves) > 0:
+		move := movePair(result.Moves[0])
+		response.Move = &move
+	default:
+		cfg := mcts.DefaultConfig()
+		if opts.Iterations > 0 {
+			cfg.Iterations = opts.Iterations
+		}
+		cfg.Autoplay = opts.Autoplay
+		if opts.Evaluator != (streets.Evaluator{}) {
+			cfg.Evaluator = opts.Evaluator
+			cfg.EvalRollouts = true
+		}
+		rootNode := mcts.Search(game, cfg, rng, time.Time{})
+		if bestMove, reward := rootNode.BestMove(); bestMove != (streets.Move{}) {
+			move := movePair(bestMove)
+			response.Move = &move
+			response.Reward = reward
+		}
+	}
+
+	return response
+}
+
+// movePair converts a move to its [from,to] form
+func movePair(move streets.Move) [2]int {
+	return [2]int{move.From, move.To}
+}
+
+// movePairs converts moves to their [from,to] form
+func movePairs(moves []streets.Move) [][2]int {
+	pairs := make([][2]int, len(moves))
+	for i, move := range moves {
+		pairs[i] = movePair(move)
+	}
+	return pairs
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestHintScoresWithTheEvaluator(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	// hint searches with a budget too small to solve the deal
+	hint := func(evaluator streets.Evaluator) HintResponse {
+		opts := Options{MaxStates: 1, Iterations: 50, Autoplay: streets.AutoplaySafe, Evaluator: evaluator}
+		response := Hint(game, opts, rand.New(rand.NewSource(1)))
+		if response.Move == nil {
+			t.Fatalf("no hint with evaluator %+v", evaluator)
+		}
+		return response
+	}
+
+	byCards := hint(streets.Evaluator{})
+	byDefault := hint(streets.DefaultEvaluator())
+	if byDefault.Reward == byCards.Reward {
+		t.Errorf("reward %v with the built-in weights, the same as scoring by cards up", byDefault.Reward)
+	}
+
+	// An evaluator that scores every position alike gives that score back
+	constant := hint(streets.Evaluator{Bias: 0.9})
+	if math.Abs(constant.Reward-0.9) > 1e-9 {
+		t.Errorf("reward %v with a constant 0.9 evaluator", constant.Reward)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// runBench plays a sample of deals with the serial search and again with
+// -threads goroutines sharing each tree, under the same time per move, and
+// reports how much stronger and faster the parallel search was
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games_fixed.txt", "file of deals separated by blank lines")
+	deals := fs.String("deals", "", "deal numbers to play instead of the input file, e.g. 1-50")
+	variantName := fs.String("variant", "streets-and-alleys", "rules to play: "+streets.VariantNames())
+	sample := fs.Int("sample", 5, "number of deals to sample from the input (0 for all)")
+	seed := fs.Int64("seed", 1, "seed for sampling deals and the searches")
+
+	// Compare on time rather than iterations, since that is what threads buy
+	defaults := mcts.DefaultConfig()
+	defaults.Iterations = 0
+	defaults.MoveTime = 200 * time.Millisecond
+	defaults.Threads = runtime.NumCPU()
+	search := addSearchFlags(fs, defaults)
+	fs.Parse(args)
+
+	parallel := search.config()
+	serial := parallel
+	serial.Threads = 1
+
+	games, err := loadGames(*inPath, *deals, []streets.Variant{parseVariantFlag(*variantName)})
+	if err != nil {
+		fmt.Printf("Error loading games: %v\n", err)
+		os.Exit(1)
+	}
+	games = sampleGames(games, *sample, rand.New(rand.NewSource(*seed)))
+	fmt.Printf("Playing %d games with 1 and %d threads\n", len(games), parallel.Threads)
+
+	serialRow := tuneSetting(games, serial, *seed, 1)
+	printBenchRow("serial", serialRow)
+	parallelRow := tuneSetting(games, parallel, *seed, 1)
+	printBenchRow("parallel", parallelRow)
+
+	if serialRow.IterationsPerSec > 0 {
+		fmt.Printf("Parallel search ran %.2fx the iterations per second and left %+.1f cards per game\n",
+			parallelRow.IterationsPerSec/serialRow.IterationsPerSec, parallelRow.AvgCardsLeft-serialRow.AvgCardsLeft)
+	}
+}
+
+// printBenchRow prints one search's results
+func printBenchRow(label string, row tuneRow) {
+	fmt.Printf("%-10s won %d of %d, %.1f cards left on average, %.0f iterations/s, %.1fs\n",
+		label+":", row.Wins, row.Games, row.AvgCardsLeft, row.IterationsPerSec, row.RuntimeSec)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// runDeal prints the layout for each of the given deal numbers, leaving out
+// deals that are dead from the start unless asked to keep them
+func runDeal(args []string) {
+	fs := flag.NewFlagSet("deal", flag.ExitOnError)
+	variantName := fs.String("variant", "streets-and-alleys", "rules to deal for: "+streets.VariantNames())
+	keepDead := fs.Bool("keep-dead", false, "print deals that can't be won too")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Println("usage: solver deal [-variant name] [-keep-dead] <numbers>, e.g. solver deal 1234,1240-1250")
+		os.Exit(2)
+	}
+	variant := parseVariantFlag(*variantName)
+
+	dealNumbers, err := parseDealNumbers(strings.Join(fs.Args(), ","))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	printed := 0
+	for _, dealNumber := range dealNumbers {
+		game := streets.StreetsGame{Variant: variant}
+		game.Deal(dealNumber)
+		if dead, reason := game.Dead(); dead && !*keepDead {
+			fmt.Fprintf(os.Stderr, "deal %d: dead, %s\n", dealNumber, reason)
+			continue
+		}
+
+		if printed > 0 {
+			fmt.Println()
+		}
+		printed++
+		fmt.Printf("deal: %d\n", dealNumber)
+		game.Print()
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// gameSpec is one deal for a command to work on
+type gameSpec struct {
+	name       string // "game 3" or "deal 1234", for messages
+	key        int64  // Game number in the input file or deal number, used to derive the search seed
+	dealNumber int64  // Deal number the layout came from, 0 if it was read from a file
+	text       string
+}
+
+// loadGames returns the numbered deals listed in deals (e.g. "1234,1240-1250"),
+// or every deal in the input file when deals is empty. Numbered deals are
+// dealt once for each of variants, in turn, sharing a key so each variant
+// gets the same shuffle and search seed. Deals in the file that don't name
+// their own variant are played as the first of variants.
+func loadGames(inPath, deals string, variants []streets.Variant) ([]gameSpec, error) {
+	if deals != "" {
+		dealNumbers, err := parseDealNumbers(deals)
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make([]gameSpec, 0, len(dealNumbers)*len(variants))
+		for _, dealNumber := range dealNumbers {
+			for _, variant := range variants {
+				game := streets.StreetsGame{Variant: variant}
+				game.Deal(dealNumber)
+
+				name := fmt.Sprintf("deal %d", dealNumber)
+				if len(variants) > 1 {
+					name += " (" + variant.String() + ")"
+				}
+				specs = append(specs, gameSpec{
+					name:       name,
+					key:        dealNumber,
+					dealNumber: dealNumber,
+					text:       game.ToString(),
+				})
+			}
+		}
+		return specs, nil
+	}
+
+	if len(variants) > 1 {
+		return nil, fmt.Errorf("comparing variants needs -deals, so each variant can be dealt the same shuffle")
+	}
+
+	content, err := os.ReadFile(inPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []gameSpec
+	for gameNum, gameStr := range splitGames(string(content)) {
+		gameStr = strings.TrimSpace(gameStr)
+		if gameStr == "" {
+			continue
+		}
+		specs = append(specs, gameSpec{
+			name: fmt.Sprintf("game %d", gameNum+1),
+			key:  int64(gameNum + 1),
+			text: withVariant(gameStr, variants[0]),
+		})
+	}
+	return specs, nil
+}
+
+// withVariant adds a "variant:" line to deal text that doesn't have one, so
+// the variant is stored with the deal and shown in the log
+func withVariant(text string, variant streets.Variant) string {
+	if variant == streets.StreetsAndAlleys || strings.Contains(text, "variant:") {
+		return text
+	}
+	return "variant: " + variant.String() + "\n" + text
+}
+
+// parseVariantFlag parses the -variant flag, exiting with a usage error if
+// it names no known variant
+func parseVariantFlag(name string) streets.Variant {
+	variant, err := streets.ParseVariant(name)
+	if err != nil {
+		fmt.Printf("%v (expected one of %s)\n", err, streets.VariantNames())
+		os.Exit(2)
+	}
+	return variant
+}
+
+// parseVariantsFlag parses a comma separated -variant list, exiting with a
+// usage error if any name is unknown
+func parseVariantsFlag(names string) []streets.Variant {
+	var variants []streets.Variant
+	for _, name := range strings.Split(names, ",") {
+		variants = append(variants, parseVariantFlag(strings.TrimSpace(name)))
+	}
+	return variants
+}
+
+// parseDealNumbers parses a comma separated list of deal numbers and ranges
+func parseDealNumbers(s string) ([]int64, error) {
+	var dealNumbers []int64
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid deal number: %q", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.ParseInt(last, 10, 64)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid deal range: %q", part)
+			}
+		}
+
+		for dealNumber := start; dealNumber <= end; dealNumber++ {
+			dealNumbers = append(dealNumbers, dealNumber)
+		}
+	}
+
+	return dealNumbers, nil
+}
+
+// gameSeed derives the search seed for one game from the run's seed, so a
+// game can be replayed on its own with the same -seed
+func gameSeed(seed, key int64) int64 {
+	return seed*1000003 + key
+}
+
+// logHeader returns the lines that identify a game in a log entry
+func (spec gameSpec) logHeader() string {
+	if spec.dealNumber != 0 {
+		return fmt.Sprintf("deal: %d\n%s", spec.dealNumber, spec.text)
+	}
+	return spec.text
+}
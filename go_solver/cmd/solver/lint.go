@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// dealBlock is one deal in a corpus file and the file line it starts on
+type dealBlock struct {
+	line int
+	text string
+}
+
+// runLint checks every deal in the input file and reports each problem found,
+// with its line and column in the file, so a corpus can be fixed before a
+// long batch run
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games.txt", "file of deals to check")
+	lenient := fs.Bool("lenient", false, "accept mid-game positions instead of requiring fresh deals")
+	variantName := fs.String("variant", "streets-and-alleys", "rules for deals that don't name one: "+streets.VariantNames())
+	fs.Parse(args)
+
+	variant := parseVariantFlag(*variantName)
+
+	content, err := os.ReadFile(*inPath)
+	if err != nil {
+		fmt.Printf("Error reading input file: %v\n", err)
+		os.Exit(1)
+	}
+
+	mode := streets.Strict
+	if *lenient {
+		mode = streets.Lenient
+	}
+
+	blocks := splitDealBlocks(string(content))
+	bad := 0
+	for gameNum, block := range blocks {
+		game := streets.StreetsGame{Variant: variant}
+		err := game.FromStringMode(block.text, mode)
+		if err == nil {
+			continue
+		}
+		bad++
+
+		var parseErr *streets.ParseError
+		if !errors.As(err, &parseErr) {
+			fmt.Printf("%s: game %d: %v\n", *inPath, gameNum+1, err)
+			continue
+		}
+		for _, problem := range parseErr.Problems {
+			// Problem lines count from the start of the deal; problems with
+			// the deal as a whole are reported at its first line
+			line := block.line
+			if problem.Line > 0 {
+				line += problem.Line - 1
+			}
+			position := fmt.Sprintf("%s:%d", *inPath, line)
+			if problem.Column > 0 {
+				position += fmt.Sprintf(":%d", problem.Column)
+			}
+			fmt.Printf("%s: game %d: %s\n", position, gameNum+1, problem.Message)
+		}
+	}
+
+	fmt.Printf("Checked %d deals, %d with problems\n", len(blocks), bad)
+	if bad > 0 {
+		os.Exit(1)
+	}
+}
+
+// splitDealBlocks splits a file of deals separated by blank lines, keeping
+// track of the line each deal starts on
+func splitDealBlocks(content string) []dealBlock {
+	var blocks []dealBlock
+	var lines []string
+	start := 0
+
+	flush := func() {
+		if len(lines) > 0 {
+			blocks = append(blocks, dealBlock{line: start, text: strings.Join(lines, "\n")})
+			lines = nil
+		}
+	}
+
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if len(lines) == 0 {
+			start = i + 1
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return blocks
+}
@@ -0,0 +1,80 @@
+// Command solver runs the Streets and Alleys solvers over a file of deals.
+// Most commands take -variant to play a sibling game such as Beleaguered Castle
+// or Citadel; solve and prove accept a list, e.g. -variant
+// streets-and-alleys,citadel -deals 1-100, to compare win rates on the same
+// shuffles and search seeds. Deals that can be shown dead from the start are
+// skipped by solve and prove and left out by deal, with the reason why.
+//
+// Usage:
+//
+//	solver [solve] [flags]  play each deal with MCTS and log the move lists
+//	solver prove [flags]    exhaustively label each deal winnable or unwinnable
+//	solver verify [flags]   replay logged solutions and check that they win
+//	solver lint [flags]     check a file of deals and report every problem found
+//	solver tune [flags]     compare search settings on a sample of deals
+//	solver bench [flags]    compare parallel search with serial on the same deals
+//	solver train [flags]    fit position evaluator weights from self-play
+//	solver deal <numbers>   print the layouts for the given deal numbers that aren't dead
+//	solver serve [flags]    answer POST /solve, /hint and /health requests over local HTTP
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func main() {
+	command := "solve"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "solve":
+		runSolve(args)
+	case "prove":
+		runProve(args)
+	case "verify":
+		runVerify(args)
+	case "lint":
+		runLint(args)
+	case "tune":
+		runTune(args)
+	case "bench":
+		runBench(args)
+	case "train":
+		runTrain(args)
+	case "deal":
+		runDeal(args)
+	case "serve":
+		runServe(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		os.Exit(2)
+	}
+}
+
+// splitGames splits a file of deals separated by blank lines
+func splitGames(content string) []string {
+	// First normalize line endings
+	normalizedContent := strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.Split(normalizedContent, "\n\n")
+}
+
+// formatMoves renders moves as a JSON-style list of [from,to] pairs
+func formatMoves(moves []streets.Move) string {
+	var result strings.Builder
+	result.WriteString("[")
+	for i, move := range moves {
+		if i > 0 {
+			result.WriteString(",")
+		}
+		fmt.Fprintf(&result, "[%d,%d]", move.From, move.To)
+	}
+	result.WriteString("]")
+	return result.String()
+}
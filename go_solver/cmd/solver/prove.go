@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/brettlyne/cards/go_solver/exhaustive"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// runProve runs the exhaustive solver on every deal in the input file, or
+// each numbered deal, and logs whether each one is winnable, with the
+// winning line when there is one
+func runProve(args []string) {
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games.txt", "file of deals separated by blank lines")
+	logPath := fs.String("log", "winnable_games_proofs.log", "file to write results to")
+	maxStates := fs.Int("max-states", 5000000, "give up on a deal after this many positions (0 for no limit)")
+	autoplayName := fs.String("autoplay", "safe", "foundation moves to play automatically: off, aces or safe")
+	deals := fs.String("deals", "", "deal numbers to prove instead of the input file, e.g. 1234,1240-1250")
+	variantNames := fs.String("variant", "streets-and-alleys", "rules to play, or a comma separated list to compare on the same -deals: "+streets.VariantNames())
+	fs.Parse(args)
+
+	autoplay, err := streets.ParseAutoplay(*autoplayName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	games, err := loadGames(*inPath, *deals, parseVariantsFlag(*variantNames))
+	if err != nil {
+		fmt.Printf("Error loading games: %v\n", err)
+		return
+	}
+
+	logFile, err := os.OpenFile(*logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		fmt.Printf("Error opening log file: %v\n", err)
+		return
+	}
+	defer logFile.Close()
+
+	// Tally results per variant, in the order the variants come up
+	var variants []streets.Variant
+	counts := make(map[streets.Variant]map[exhaustive.Status]int)
+	for _, spec := range games {
+		game, err := streets.Parse(spec.text)
+		if err != nil {
+			fmt.Printf("Error parsing %s: %v\n", spec.name, err)
+			continue
+		}
+
+		if counts[game.Variant] == nil {
+			variants = append(variants, game.Variant)
+			counts[game.Variant] = make(map[exhaustive.Status]int)
+		}
+
+		// A dead deal is unwinnable without searching it
+		var result exhaustive.Result
+		dead, reason := game.Dead()
+		if dead {
+			result.Status = exhaustive.Unwinnable
+			fmt.Printf("%s: %s, dead: %s\n", spec.name, result.Status, reason)
+		} else {
+			result = exhaustive.Solve(game, *maxStates, autoplay)
+			fmt.Printf("%s: %s (%d states, %d moves)\n", spec.name, result.Status, result.States, len(result.Moves))
+		}
+		counts[game.Variant][result.Status]++
+
+		entry := spec.logHeader() + "\nresult: " + result.Status.String()
+		if dead {
+			entry += "\ndead: " + reason
+		}
+		if result.Status == exhaustive.Winnable {
+			entry += "\nmoves: " + formatMoves(result.Moves)
+		}
+		entry += "\n\n"
+		if _, err := logFile.WriteString(entry); err != nil {
+			fmt.Printf("Error writing to log: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Done! Results written to %s\n", *logPath)
+	for _, variant := range variants {
+		c := counts[variant]
+		total := c[exhaustive.Winnable] + c[exhaustive.Unwinnable] + c[exhaustive.Unknown]
+		fmt.Printf("  %s: %d winnable, %d unwinnable, %d unknown (%.1f%% of %d proven winnable)\n",
+			variant, c[exhaustive.Winnable], c[exhaustive.Unwinnable], c[exhaustive.Unknown],
+			100*float64(c[exhaustive.Winnable])/float64(total), total)
+	}
+}
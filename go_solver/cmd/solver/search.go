@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// searchFlags are the MCTS parameters and budgets a command accepts
+type searchFlags struct {
+	cfg      mcts.Config
+	fs       *flag.FlagSet
+	autoplay *string
+	rollout  *string
+	weights  *string
+}
+
+// addSearchFlags registers the MCTS flags on fs, defaulting to defaults
+func addSearchFlags(fs *flag.FlagSet, defaults mcts.Config) *searchFlags {
+	f := &searchFlags{cfg: defaults, fs: fs}
+	fs.IntVar(&f.cfg.Iterations, "iterations", f.cfg.Iterations, "MCTS iterations per move (0 for no limit with -move-time or -game-time)")
+	fs.IntVar(&f.cfg.MaxRolloutLength, "rollout-length", f.cfg.MaxRolloutLength, "maximum moves in a simulated playout")
+	fs.IntVar(&f.cfg.RolloutCutoff, "rollout-cutoff", f.cfg.RolloutCutoff, "moves after which a playout stops and the position evaluator scores it (0 plays on to -rollout-length)")
+	fs.Float64Var(&f.cfg.ExplorationConstant, "exploration", f.cfg.ExplorationConstant, "UCT exploration constant")
+	fs.Float64Var(&f.cfg.RewardPower, "reward-power", f.cfg.RewardPower, "raise rollout rewards to this power (above 1 favours near wins)")
+	fs.Float64Var(&f.cfg.LimitBonus, "limit-bonus", f.cfg.LimitBonus, "cards credited to a rollout that reaches -rollout-length, when scored by the cards up")
+	fs.IntVar(&f.cfg.MaxMoves, "max-moves", f.cfg.MaxMoves, "moves to play in a game before giving up")
+	fs.DurationVar(&f.cfg.MoveTime, "move-time", f.cfg.MoveTime, "search time per move, e.g. 2s (0 for no limit)")
+	fs.DurationVar(&f.cfg.GameTime, "game-time", f.cfg.GameTime, "total time per game, e.g. 30s (0 for no limit)")
+	fs.IntVar(&f.cfg.Threads, "threads", f.cfg.Threads, "goroutines searching each move's tree together")
+	fs.IntVar(&f.cfg.Ensemble, "ensemble", f.cfg.Ensemble, "independent trees to search per move, voting with their visit counts")
+	fs.BoolVar(&f.cfg.Transpositions, "transpositions", f.cfg.Transpositions, "share statistics between move orders that reach the same position")
+	fs.BoolVar(&f.cfg.ReuseTree, "reuse-tree", f.cfg.ReuseTree, "carry the subtree of the move played over to the next move's search")
+	fs.Float64Var(&f.cfg.RAVEEquivalence, "rave", f.cfg.RAVEEquivalence, "visits at which RAVE's all-moves-as-first values and a move's own average weigh the same, e.g. 300 (0 turns RAVE off)")
+	f.autoplay = fs.String("autoplay", f.cfg.Autoplay.String(), "foundation moves to play automatically: off, aces or safe")
+	f.weights = fs.String("weights", defaultWeightsPath, "position evaluator weights written by train; if the file is there, rollouts are scored with them unless -eval-rollouts=false")
+	fs.BoolVar(&f.cfg.EvalRollouts, "eval-rollouts", f.cfg.EvalRollouts, "score where rollouts end with the position evaluator rather than by the cards up (always so with -rollout-cutoff or -leaf-weight)")
+	fs.Float64Var(&f.cfg.LeafWeight, "leaf-weight", f.cfg.LeafWeight, "weight of the position evaluator's score of each new leaf against its rollout (1 skips rollouts)")
+	f.rollout = fs.String("rollout", f.cfg.Rollout.String(), "how rollouts choose moves: random, foundation, no-bury, empty-row or mixed")
+	fs.Float64Var(&f.cfg.RolloutEpsilon, "rollout-epsilon", f.cfg.RolloutEpsilon, "chance a heuristic rollout plays a random move instead of its best one")
+	fs.Float64Var(&f.cfg.RolloutTemperature, "rollout-temperature", f.cfg.RolloutTemperature, "pick heuristic rollout moves by softmax at this temperature instead of epsilon-greedy (0 for epsilon-greedy)")
+	return f
+}
+
+// defaultWeightsPath is where train saves evaluator weights and where the
+// other commands look for them
+const defaultWeightsPath = "eval_weights.json"
+
+// loadEvaluator reads evaluator weights written by train, reporting whether
+// they came from the file. If there is no file at the default path the
+// built-in weights are used.
+func loadEvaluator(path string) (streets.Evaluator, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && path == defaultWeightsPath {
+		return streets.DefaultEvaluator(), false, nil
+	}
+	if err != nil {
+		return streets.Evaluator{}, false, fmt.Errorf("reading evaluator weights: %w", err)
+	}
+
+	var evaluator streets.Evaluator
+	if err := json.Unmarshal(data, &evaluator); err != nil {
+		return streets.Evaluator{}, false, fmt.Errorf("reading evaluator weights from %s: %w", path, err)
+	}
+	return evaluator, true, nil
+}
+
+// config returns the search settings once the flags have been parsed,
+// exiting with a usage error if they can't be used
+func (f *searchFlags) config() mcts.Config {
+	autoplay, err := streets.ParseAutoplay(*f.autoplay)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	f.cfg.Autoplay = autoplay
+
+	rollout, err := mcts.ParseRolloutPolicy(*f.rollout)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	f.cfg.Rollout = rollout
+
+	evaluator, loaded, err := loadEvaluator(*f.weights)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	f.cfg.Evaluator = evaluator
+
+	// Trained weights are there to be used, so score rollouts with them
+	// unless some other use of the evaluator was asked for
+	if loaded && !f.isSet("eval-rollouts") && f.cfg.RolloutCutoff == 0 && f.cfg.LeafWeight == 0 {
+		fmt.Printf("Scoring rollouts with the evaluator weights in %s\n", *f.weights)
+		f.cfg.EvalRollouts = true
+	}
+
+	if err := f.cfg.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	return f.cfg
+}
+
+// isSet reports whether the named flag was given on the command line
+func (f *searchFlags) isSet(name string) bool {
+	set := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestSearchFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	search := addSearchFlags(fs, mcts.DefaultConfig())
+	err := fs.Parse([]string{
+		"-iterations", "0", "-move-time", "2s", "-game-time", "30s", "-exploration", "1.2",
+		"-rollout-length", "80", "-max-moves", "100", "-autoplay", "aces", "-rollout", "no-bury",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := search.config()
+	want := mcts.DefaultConfig()
+	want.Iterations = 0
+	want.MoveTime = 2 * time.Second
+	want.GameTime = 30 * time.Second
+	want.ExplorationConstant = 1.2
+	want.MaxRolloutLength = 80
+	want.MaxMoves = 100
+	want.Autoplay = streets.AutoplayAces
+	want.Rollout = mcts.RolloutNoBury
+	if cfg != want {
+		t.Errorf("config %+v\nwant %+v", cfg, want)
+	}
+}
+
+func TestSearchFlagsUseTrainedWeights(t *testing.T) {
+	trained := streets.Evaluator{Bias: 0.25, Logistic: true}
+	trained.Weights[0] = 2
+	data, err := json.Marshal(trained)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "weights.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args         []string
+		evaluator    streets.Evaluator
+		evalRollouts bool
+	}{
+		{nil, streets.DefaultEvaluator(), false},
+		{[]string{"-weights", path}, trained, true},
+		{[]string{"-weights", path, "-eval-rollouts=false"}, trained, false},
+		{[]string{"-weights", path, "-rollout-cutoff", "20"}, trained, false},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		search := addSearchFlags(fs, mcts.DefaultConfig())
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		cfg := search.config()
+		if cfg.Evaluator != tt.evaluator || cfg.EvalRollouts != tt.evalRollouts {
+			t.Errorf("%q: evaluator %+v with eval rollouts %v, want %+v and %v",
+				tt.args, cfg.Evaluator, cfg.EvalRollouts, tt.evaluator, tt.evalRollouts)
+		}
+	}
+}
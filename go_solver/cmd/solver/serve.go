@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/api"
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// maxRequestBytes bounds the size of a request body
+const maxRequestBytes = 1 << 16
+
+// runServe starts a local HTTP service that answers solve, hint and health
+// requests from the frontend
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	maxStates := fs.Int("max-states", 1000000, "positions the exhaustive solver may visit per solve request (about 300 bytes each)")
+	hintStates := fs.Int("hint-states", 100000, "positions the exhaustive solver may visit per hint request")
+	maxSearches := fs.Int("max-searches", 2, "solve and hint requests to search at once; more wait their turn")
+	autoplayName := fs.String("autoplay", "safe", "foundation moves to play automatically: off, aces or safe")
+	origin := fs.String("origin", "http://localhost:5173", "origin of the frontend allowed to call the service (the Vite dev server by default)")
+	weightsPath := fs.String("weights", defaultWeightsPath, "position evaluator weights written by train, for health requests and, if the file is there, to score hint searches")
+	fs.Parse(args)
+
+	evaluator, loaded, err := loadEvaluator(*weightsPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	autoplay, err := streets.ParseAutoplay(*autoplayName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	if *maxSearches < 1 {
+		fmt.Println("-max-searches must be at least 1")
+		os.Exit(2)
+	}
+	searches := make(chan struct{}, *maxSearches)
+
+	solveOpts := api.Options{MaxStates: *maxStates, Autoplay: autoplay}
+	hintOpts := api.Options{MaxStates: *hintStates, Iterations: mcts.DefaultConfig().Iterations, Autoplay: autoplay}
+	if loaded {
+		hintOpts.Evaluator = evaluator
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", handlePosition(*origin, searches, func(game streets.StreetsGame) any {
+		return api.Solve(game, solveOpts)
+	}))
+	mux.HandleFunc("/hint", handlePosition(*origin, searches, func(game streets.StreetsGame) any {
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		return api.Hint(game, hintOpts, rng)
+	}))
+
+	mux.HandleFunc("/health", handlePosition(*origin, nil, func(game streets.StreetsGame) any {
+		return api.Health(game, evaluator)
+	}))
+
+	log.Printf("Solver listening on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// handlePosition wraps a solver call as a handler that accepts a POSTed
+// api.Position and responds with the call's result as JSON. Only the
+// frontend at origin may call it from a browser, so other pages the user
+// visits can't drive the solver. When slots is not nil, at most cap(slots)
+// calls run at once, bounding the memory the searches use.
+func handlePosition(origin string, slots chan struct{}, answer func(streets.StreetsGame) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The frontend is served from a different local port
+		if requestOrigin := r.Header.Get("Origin"); requestOrigin != "" && requestOrigin != origin {
+			writeError(w, http.StatusForbidden, "origin not allowed")
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Vary", "Origin")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+
+		var position api.Position
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&position); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+			return
+		}
+
+		game, err := position.Game()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-r.Context().Done():
+				writeError(w, http.StatusServiceUnavailable, "gave up waiting for a free solver")
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, answer(game))
+	}
+}
+
+// writeJSON writes value as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+const testOrigin = "http://localhost:5173"
+
+// countCards answers with the number of cards in the rows
+func countCards(game streets.StreetsGame) any {
+	return map[string]int{"cards": game.CountCardsInRows()}
+}
+
+func TestHandlePosition(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(1)
+	deal, err := json.Marshal(map[string]string{"deal": game.ToString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		origin string
+		body   string
+		status int
+		want   string // Substring of the response body
+	}{
+		{"solve from the frontend", http.MethodPost, testOrigin, string(deal), http.StatusOK, `"cards":52`},
+		{"no origin header", http.MethodPost, "", string(deal), http.StatusOK, `"cards":52`},
+		{"another origin", http.MethodPost, "http://evil.example", string(deal), http.StatusForbidden, "origin not allowed"},
+		{"preflight", http.MethodOptions, testOrigin, "", http.StatusNoContent, ""},
+		{"get", http.MethodGet, testOrigin, "", http.StatusMethodNotAllowed, "use POST"},
+		{"bad json", http.MethodPost, testOrigin, `{"deal":`, http.StatusBadRequest, "invalid request"},
+		{"bad position", http.MethodPost, testOrigin, `{"rows":[["AH","AH"]]}`, http.StatusBadRequest, "duplicate card AH"},
+		{"body too large", http.MethodPost, testOrigin, `{"deal":"` + strings.Repeat("x", maxRequestBytes) + `"}`, http.StatusBadRequest, "too large"},
+	}
+
+	handler := handlePosition(testOrigin, nil, countCards)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/solve", strings.NewReader(tt.body))
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("body %q doesn't contain %q", w.Body, tt.want)
+			}
+			if tt.status != http.StatusForbidden && w.Header().Get("Access-Control-Allow-Origin") != testOrigin {
+				t.Errorf("Access-Control-Allow-Origin is %q", w.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestHandlePositionWaitsForASlot(t *testing.T) {
+	slots := make(chan struct{}, 1)
+	called := false
+	handler := handlePosition(testOrigin, slots, func(game streets.StreetsGame) any {
+		called = true
+		return countCards(game)
+	})
+
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(1)
+	deal, err := json.Marshal(map[string]string{"deal": game.ToString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With the only slot taken, a request whose client has gone away gives up
+	slots <- struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(string(deal))).WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable || called {
+		t.Errorf("status %d, called %v with no free slot", w.Code, called)
+	}
+
+	// Once the slot is free the request is answered and gives the slot back
+	<-slots
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(string(deal))))
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("status %d, called %v with a free slot", w.Code, called)
+	}
+	if len(slots) != 0 {
+		t.Errorf("%d slots still taken", len(slots))
+	}
+}
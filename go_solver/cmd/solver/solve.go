@@ -0,0 +1,183 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// runSolve plays every deal in the input file, or each numbered deal, with
+// MCTS and logs the resulting move lists
+func runSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games_fixed.txt", "file of deals separated by blank lines")
+	logPath := fs.String("log", "winnable_games_moves.log", "file to write move lists to")
+	workers := fs.Int("workers", 1, "number of games to solve in parallel")
+	deals := fs.String("deals", "", "deal numbers to solve instead of the input file, e.g. 1234,1240-1250")
+	variantNames := fs.String("variant", "streets-and-alleys", "rules to play, or a comma separated list to compare on the same -deals: "+streets.VariantNames())
+	seed := fs.Int64("seed", 0, "search seed (0 picks one from the clock)")
+	search := addSearchFlags(fs, mcts.DefaultConfig())
+	fs.Parse(args)
+
+	if *workers < 1 {
+		fmt.Println("-workers must be at least 1")
+		os.Exit(2)
+	}
+
+	cfg := search.config()
+
+	// Read the deals
+	games, err := loadGames(*inPath, *deals, parseVariantsFlag(*variantNames))
+	if err != nil {
+		fmt.Printf("Error loading games: %v\n", err)
+		return
+	}
+	fmt.Printf("Found %d games to analyze\n", len(games))
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("Search seed: %d\n", *seed)
+
+	// Set up logging
+	logFile, err := os.OpenFile(*logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		fmt.Printf("Error opening log file: %v\n", err)
+		return
+	}
+	defer logFile.Close()
+
+	// Each game gets its own generator seeded from the run seed and the game,
+	// so a game plays out the same however many workers are running
+	// Fan the games out over the workers
+	jobs := make(chan int)
+	results := make(chan gameResult)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gameNum := range jobs {
+				rng := rand.New(rand.NewSource(gameSeed(*seed, games[gameNum].key)))
+				results <- solveGame(gameNum, games[gameNum], cfg, rng)
+			}
+		}()
+	}
+	go func() {
+		for gameNum := range games {
+			jobs <- gameNum
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Write results in input order as soon as each one's predecessors are
+	// done, tallying wins per variant in the order the variants come up
+	var variants []streets.Variant
+	played := make(map[streets.Variant]int)
+	won := make(map[streets.Variant]int)
+	pending := make(map[int]gameResult)
+	nextGame := 0
+	searched, disagreements := 0, 0
+	for result := range results {
+		pending[result.gameNum] = result
+		for result, ok := pending[nextGame]; ok; result, ok = pending[nextGame] {
+			delete(pending, nextGame)
+			nextGame++
+			if result.entry == "" {
+				continue // Game failed to parse
+			}
+
+			if _, seen := played[result.variant]; !seen {
+				variants = append(variants, result.variant)
+			}
+			played[result.variant]++
+			if result.won {
+				won[result.variant]++
+			}
+			searched += result.searched
+			disagreements += result.disagreements
+
+			if _, err := logFile.WriteString(result.entry); err != nil {
+				fmt.Printf("Error writing to log: %v\n", err)
+			}
+		}
+	}
+
+	fmt.Printf("Done! Results have been written to %s\n", *logPath)
+	for _, variant := range variants {
+		fmt.Printf("  %s: won %d of %d (%.1f%%)\n",
+			variant, won[variant], played[variant], 100*float64(won[variant])/float64(played[variant]))
+	}
+	if cfg.Ensemble > 1 && searched > 0 {
+		fmt.Printf("Ensembles disagreed on %d of %d moves (%.1f%%)\n",
+			disagreements, searched, 100*float64(disagreements)/float64(searched))
+	}
+}
+
+// gameResult is a finished game's log entry, empty if the game failed to
+// parse, whether every card reached the foundations, and on how many of its
+// searched moves an ensemble disagreed
+type gameResult struct {
+	gameNum       int
+	entry         string
+	variant       streets.Variant
+	won           bool
+	searched      int
+	disagreements int
+}
+
+// solveGame plays one deal with MCTS and returns its log entry
+func solveGame(gameNum int, spec gameSpec, cfg mcts.Config, rng *rand.Rand) gameResult {
+	fmt.Printf("\nProcessing %s (%d lines):\n%s\n", spec.name, len(strings.Split(spec.text, "\n")), spec.text)
+
+	// Parse the game
+	game, err := streets.Parse(spec.text)
+	if err != nil {
+		fmt.Printf("Error parsing %s: %v\n", spec.name, err)
+		return gameResult{gameNum: gameNum}
+	}
+
+	// Don't spend a search on a deal that can't be won
+	if dead, reason := game.Dead(); dead {
+		fmt.Printf("%s: skipped, dead: %s\n", spec.name, reason)
+		return gameResult{
+			gameNum: gameNum,
+			entry:   spec.logHeader() + "\ndead: " + reason + "\n\n",
+			variant: game.Variant,
+		}
+	}
+
+	// Play through the game, printing progress every 50 moves
+	result := mcts.PlayGame(game, cfg, rng, func(moveNum int, state streets.StreetsGame) {
+		if moveNum%50 == 0 {
+			fmt.Printf("  %s: made %d moves, cards in rows: %d\n", spec.name, moveNum, state.CountCardsInRows())
+		}
+	})
+	if result.Stop != mcts.StopWon && result.Stop != mcts.StopMaxMoves {
+		fmt.Printf("%s: stopped after %d moves: %s\n", spec.name, result.Searched, result.Stop)
+	}
+
+	fmt.Printf("Completed %s with %d moves\n", spec.name, len(result.Moves))
+	if cfg.Ensemble > 1 {
+		fmt.Printf("%s: ensemble disagreed on %d of %d moves\n", spec.name, result.Disagreements, result.Searched)
+	}
+
+	// Log the game and its moves
+	return gameResult{
+		gameNum:       gameNum,
+		entry:         spec.logHeader() + "\nmoves: " + formatMoves(result.Moves) + "\n\n",
+		variant:       game.Variant,
+		won:           result.Won(),
+		searched:      result.Searched,
+		disagreements: result.Disagreements,
+	}
+}
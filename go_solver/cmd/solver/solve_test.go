@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSolveLogDoesNotDependOnWorkers(t *testing.T) {
+	dir := t.TempDir()
+	logs := make(map[string]string)
+	for _, workers := range []string{"1", "3"} {
+		logPath := filepath.Join(dir, "workers"+workers+".log")
+		runSolve([]string{
+			"-deals", "2-9", "-workers", workers, "-seed", "7", "-log", logPath,
+			"-iterations", "10", "-max-moves", "15",
+		})
+
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs[workers] = string(content)
+	}
+
+	if logs["1"] != logs["3"] {
+		t.Errorf("log with 3 workers differs from 1 worker:\n%s\nwant:\n%s", logs["3"], logs["1"])
+	}
+
+	// Entries come out in deal order
+	last := 0
+	for _, line := range strings.Split(logs["1"], "\n") {
+		if !strings.HasPrefix(line, "deal: ") {
+			continue
+		}
+		dealNumber, err := strconv.Atoi(strings.TrimPrefix(line, "deal: "))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dealNumber <= last {
+			t.Errorf("deal %d logged after deal %d", dealNumber, last)
+		}
+		last = dealNumber
+	}
+	if last != 9 {
+		t.Errorf("last deal logged is %d, want 9", last)
+	}
+}
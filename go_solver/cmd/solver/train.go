@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// sample is a position seen in self-play and how its game turned out
+type sample struct {
+	features streets.Features
+	outcome  float64
+}
+
+// runTrain plays deals with the solver, recording the features of every
+// position it reaches and how each game ended, fits evaluator weights that
+// predict the outcome from the features, and saves them for the other
+// commands to load
+func runTrain(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games_fixed.txt", "file of deals separated by blank lines")
+	deals := fs.String("deals", "", "deal numbers to play instead of the input file, e.g. 1-50")
+	variantName := fs.String("variant", "streets-and-alleys", "rules to play: "+streets.VariantNames())
+	sampleSize := fs.Int("sample", 0, "number of deals to sample from the input (0 for all)")
+	workers := fs.Int("workers", 1, "number of games to play in parallel")
+	seed := fs.Int64("seed", 1, "seed for sampling deals and the searches")
+	model := fs.String("model", "logistic", "model to fit: linear or logistic")
+	target := fs.String("target", "cards", "outcome to predict: cards (fraction of cards that got up) or win")
+	epochs := fs.Int("epochs", 2000, "gradient descent steps for the logistic model")
+	learningRate := fs.Float64("learning-rate", 1, "gradient descent step size for the logistic model")
+	l2 := fs.Float64("l2", 0.001, "L2 penalty on the weights")
+	outPath := fs.String("out", defaultWeightsPath, "file to save the weights to")
+	search := addSearchFlags(fs, mcts.DefaultConfig())
+	fs.Parse(args)
+
+	cfg := search.config()
+	if *model != "linear" && *model != "logistic" {
+		fmt.Printf("unknown model %q, expected linear or logistic\n", *model)
+		os.Exit(2)
+	}
+	if *target != "cards" && *target != "win" {
+		fmt.Printf("unknown target %q, expected cards or win\n", *target)
+		os.Exit(2)
+	}
+	if *workers < 1 {
+		fmt.Println("-workers must be at least 1")
+		os.Exit(2)
+	}
+
+	games, err := loadGames(*inPath, *deals, []streets.Variant{parseVariantFlag(*variantName)})
+	if err != nil {
+		fmt.Printf("Error loading games: %v\n", err)
+		os.Exit(1)
+	}
+	games = sampleGames(games, *sampleSize, rand.New(rand.NewSource(*seed)))
+
+	fmt.Printf("Playing %d games\n", len(games))
+	samples, wins := selfPlay(games, cfg, *seed, *workers, *target == "win")
+	fmt.Printf("Recorded %d positions from %d games, %d won\n", len(samples), len(games), wins)
+	if len(samples) == 0 {
+		fmt.Println("No positions to train on")
+		os.Exit(1)
+	}
+
+	var evaluator streets.Evaluator
+	if *model == "linear" {
+		evaluator, err = fitLinear(samples, *l2)
+		if err != nil {
+			fmt.Printf("Error fitting weights: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		evaluator = fitLogistic(samples, *epochs, *learningRate, *l2)
+	}
+
+	// Report the fit next to the built-in weights
+	fmt.Printf("Mean absolute error: %.4f (built-in weights: %.4f)\n",
+		meanError(evaluator, samples), meanError(streets.DefaultEvaluator(), samples))
+	fmt.Printf("  %-12s %8.4f\n", "bias", evaluator.Bias)
+	for i, name := range streets.FeatureNames {
+		fmt.Printf("  %-12s %8.4f\n", name, evaluator.Weights[i])
+	}
+
+	data, err := json.MarshalIndent(evaluator, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding weights: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*outPath, append(data, '\n'), 0644); err != nil {
+		fmt.Printf("Error writing weights: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Weights written to %s\n", *outPath)
+}
+
+// selfPlay plays each game with the solver and returns every position it
+// reached, labelled with the game's outcome: 1 or 0 for a win or loss when
+// winOnly is set, otherwise the fraction of the cards that got up
+func selfPlay(games []gameSpec, cfg mcts.Config, seed int64, workers int, winOnly bool) ([]sample, int) {
+	jobs := make(chan int)
+	results := make(chan playResult)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gameNum := range jobs {
+				results <- playForSamples(gameNum, games[gameNum], cfg, seed, winOnly)
+			}
+		}()
+	}
+	go func() {
+		for gameNum := range games {
+			jobs <- gameNum
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Collect by game so the samples come out in input order however the
+	// workers finish
+	byGame := make([][]sample, len(games))
+	wins := 0
+	for result := range results {
+		byGame[result.gameNum] = result.samples
+		if result.won {
+			wins++
+		}
+	}
+
+	var samples []sample
+	for _, labelled := range byGame {
+		samples = append(samples, labelled...)
+	}
+	return samples, wins
+}
+
+// playResult is the labelled positions of one self-play game
+type playResult struct {
+	gameNum int
+	samples []sample
+	won     bool
+}
+
+// playForSamples plays one game for selfPlay. A game that doesn't parse
+// gives no samples.
+func playForSamples(gameNum int, spec gameSpec, cfg mcts.Config, seed int64, winOnly bool) playResult {
+	game, err := streets.Parse(spec.text)
+	if err != nil {
+		fmt.Printf("Error parsing %s: %v\n", spec.name, err)
+		return playResult{gameNum: gameNum}
+	}
+
+	positions := []streets.Features{game.Features()}
+	rng := rand.New(rand.NewSource(gameSeed(seed, spec.key)))
+	result := mcts.PlayGame(game, cfg, rng, func(moveNum int, state streets.StreetsGame) {
+		positions = append(positions, state.Features())
+	})
+
+	outcome := float64(52-result.Final.CountCardsInRows()) / 52
+	if winOnly {
+		outcome = 0
+		if result.Won() {
+			outcome = 1
+		}
+	}
+	fmt.Printf("%s: %d positions, %d cards left\n", spec.name, len(positions), result.Final.CountCardsInRows())
+
+	labelled := make([]sample, len(positions))
+	for i, features := range positions {
+		labelled[i] = sample{features: features, outcome: outcome}
+	}
+	return playResult{gameNum: gameNum, samples: labelled, won: result.Won()}
+}
+
+// fitLinear fits the weights by ridge regression, solving the normal
+// equations directly
+func fitLinear(samples []sample, l2 float64) (streets.Evaluator, error) {
+	// Column 0 is the bias, then one column per feature
+	const n = streets.NumFeatures + 1
+	var a [n][n + 1]float64 // Augmented matrix [X'X + l2 I | X'y]
+	for _, s := range samples {
+		x := inputs(s.features)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += x[i] * x[j]
+			}
+			a[i][n] += x[i] * s.outcome
+		}
+	}
+	for i := 1; i < n; i++ {
+		a[i][i] += l2 * float64(len(samples))
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return streets.Evaluator{}, fmt.Errorf("features don't vary enough to fit; try a larger sample or -l2")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		for row := 0; row < n; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+		}
+	}
+
+	evaluator := streets.Evaluator{Bias: a[0][n] / a[0][0]}
+	for i := range evaluator.Weights {
+		evaluator.Weights[i] = a[i+1][n] / a[i+1][i+1]
+	}
+	return evaluator, nil
+}
+
+// fitLogistic fits the weights by batch gradient descent on the
+// cross-entropy between the predictions and the outcomes
+func fitLogistic(samples []sample, epochs int, learningRate, l2 float64) streets.Evaluator {
+	const n = streets.NumFeatures + 1
+	var w [n]float64
+	for epoch := 0; epoch < epochs; epoch++ {
+		var gradient [n]float64
+		for _, s := range samples {
+			x := inputs(s.features)
+			z := 0.0
+			for i := range w {
+				z += w[i] * x[i]
+			}
+			diff := 1/(1+math.Exp(-z)) - s.outcome
+			for i := range gradient {
+				gradient[i] += diff * x[i]
+			}
+		}
+		for i := range w {
+			gradient[i] /= float64(len(samples))
+			if i > 0 {
+				gradient[i] += l2 * w[i]
+			}
+			w[i] -= learningRate * gradient[i]
+		}
+	}
+
+	evaluator := streets.Evaluator{Bias: w[0], Logistic: true}
+	copy(evaluator.Weights[:], w[1:])
+	return evaluator
+}
+
+// inputs puts a constant 1 for the bias in front of the features
+func inputs(f streets.Features) [streets.NumFeatures + 1]float64 {
+	var x [streets.NumFeatures + 1]float64
+	x[0] = 1
+	copy(x[1:], f[:])
+	return x
+}
+
+// meanError is how far evaluator's scores are from the outcomes on average
+func meanError(evaluator streets.Evaluator, samples []sample) float64 {
+	total := 0.0
+	for _, s := range samples {
+		total += math.Abs(evaluator.Score(s.features) - s.outcome)
+	}
+	return total / float64(len(samples))
+}
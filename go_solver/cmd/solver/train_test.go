@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestFitLinear(t *testing.T) {
+	want := streets.Evaluator{Bias: 0.1, Weights: streets.Features{0.8, -0.05, 0.02, 0.01, -0.03}}
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]sample, 200)
+	for i := range samples {
+		var f streets.Features
+		for j := range f {
+			f[j] = rng.Float64()
+		}
+		outcome := want.Bias
+		for j := range f {
+			outcome += want.Weights[j] * f[j]
+		}
+		samples[i] = sample{features: f, outcome: outcome}
+	}
+
+	got, err := fitLinear(samples, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got.Bias-want.Bias) > 1e-9 {
+		t.Errorf("bias %v, want %v", got.Bias, want.Bias)
+	}
+	for i := range want.Weights {
+		if math.Abs(got.Weights[i]-want.Weights[i]) > 1e-9 {
+			t.Errorf("%s weight %v, want %v", streets.FeatureNames[i], got.Weights[i], want.Weights[i])
+		}
+	}
+
+	// Without a penalty, features that never change can't be fitted
+	same := []sample{{outcome: 0.5}, {outcome: 0.7}}
+	if _, err := fitLinear(same, 0); err == nil {
+		t.Error("fitted features that don't vary")
+	}
+}
+
+func TestFitLogistic(t *testing.T) {
+	// Games with more than half the cards up are won
+	var samples []sample
+	for i := 0; i <= 20; i++ {
+		var f streets.Features
+		f[0] = float64(i) / 20
+		outcome := 0.0
+		if f[0] > 0.5 {
+			outcome = 1
+		}
+		samples = append(samples, sample{features: f, outcome: outcome})
+	}
+
+	evaluator := fitLogistic(samples, 2000, 5, 0)
+	if !evaluator.Logistic {
+		t.Error("logistic fit gave a linear evaluator")
+	}
+	for _, s := range samples {
+		if score := evaluator.Score(s.features); (score > 0.5) != (s.outcome == 1) {
+			t.Errorf("cards up %v scored %v, outcome %v", s.features[0], score, s.outcome)
+		}
+	}
+	if e := meanError(evaluator, samples); e > 0.15 {
+		t.Errorf("mean error %v", e)
+	}
+}
+
+func TestSelfPlayDoesNotDependOnWorkers(t *testing.T) {
+	games, err := loadGames("", "2-7", []streets.Variant{streets.StreetsAndAlleys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := mcts.DefaultConfig()
+	cfg.Iterations = 10
+	cfg.MaxMoves = 15
+
+	serial, serialWins := selfPlay(games, cfg, 7, 1, false)
+	parallel, parallelWins := selfPlay(games, cfg, 7, 3, false)
+	if len(serial) == 0 {
+		t.Fatal("no samples")
+	}
+	if !reflect.DeepEqual(serial, parallel) || serialWins != parallelWins {
+		t.Error("samples with 3 workers differ from 1 worker")
+	}
+
+	// Each game's positions start with its deal
+	first, err := streets.Parse(games[0].text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial[0].features != first.Features() {
+		t.Errorf("first sample %v, want the first deal's %v", serial[0].features, first.Features())
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// tuneParams maps each parameter tune can vary to the Config field it sets
+var tuneParams = map[string]func(cfg *mcts.Config, value float64){
+	"exploration":         func(cfg *mcts.Config, value float64) { cfg.ExplorationConstant = value },
+	"iterations":          func(cfg *mcts.Config, value float64) { cfg.Iterations = int(value) },
+	"rollout-length":      func(cfg *mcts.Config, value float64) { cfg.MaxRolloutLength = int(value) },
+	"rollout-cutoff":      func(cfg *mcts.Config, value float64) { cfg.RolloutCutoff = int(value) },
+	"reward-power":        func(cfg *mcts.Config, value float64) { cfg.RewardPower = value },
+	"limit-bonus":         func(cfg *mcts.Config, value float64) { cfg.LimitBonus = value },
+	"max-moves":           func(cfg *mcts.Config, value float64) { cfg.MaxMoves = int(value) },
+	"threads":             func(cfg *mcts.Config, value float64) { cfg.Threads = int(value) },
+	"ensemble":            func(cfg *mcts.Config, value float64) { cfg.Ensemble = int(value) },
+	"transpositions":      func(cfg *mcts.Config, value float64) { cfg.Transpositions = value != 0 },
+	"reuse-tree":          func(cfg *mcts.Config, value float64) { cfg.ReuseTree = value != 0 },
+	"rave":                func(cfg *mcts.Config, value float64) { cfg.RAVEEquivalence = value },
+	"leaf-weight":         func(cfg *mcts.Config, value float64) { cfg.LeafWeight = value },
+	"eval-rollouts":       func(cfg *mcts.Config, value float64) { cfg.EvalRollouts = value != 0 },
+	"rollout-epsilon":     func(cfg *mcts.Config, value float64) { cfg.RolloutEpsilon = value },
+	"rollout-temperature": func(cfg *mcts.Config, value float64) { cfg.RolloutTemperature = value },
+}
+
+// integerParams are the parameters that only take whole numbers
+var integerParams = map[string]bool{"iterations": true, "rollout-length": true, "rollout-cutoff": true, "max-moves": true, "threads": true, "ensemble": true, "transpositions": true, "reuse-tree": true, "eval-rollouts": true}
+
+// sweep is the values to try for one parameter: a list such as "1.4,1.8",
+// or a range such as "1:2.5" that random search draws from
+type sweep struct {
+	name     string
+	values   []float64
+	min, max float64
+	isRange  bool
+}
+
+// draw picks a value for random search
+func (s sweep) draw(rng *rand.Rand) float64 {
+	if !s.isRange {
+		return s.values[rng.Intn(len(s.values))]
+	}
+	if integerParams[s.name] {
+		return float64(int(s.min) + rng.Intn(int(s.max)-int(s.min)+1))
+	}
+	return s.min + rng.Float64()*(s.max-s.min)
+}
+
+// sweepFlags collects repeated -vary name=values flags
+type sweepFlags []sweep
+
+func (f *sweepFlags) String() string {
+	var parts []string
+	for _, s := range *f {
+		parts = append(parts, s.name)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *sweepFlags) Set(value string) error {
+	name, spec, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected name=values, e.g. exploration=1.4,1.8")
+	}
+	if tuneParams[name] == nil {
+		names := make([]string, 0, len(tuneParams))
+		for param := range tuneParams {
+			names = append(names, param)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown parameter %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+
+	s := sweep{name: name}
+	if low, high, isRange := strings.Cut(spec, ":"); isRange {
+		var err error
+		if s.min, err = strconv.ParseFloat(low, 64); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if s.max, err = strconv.ParseFloat(high, 64); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if s.max < s.min {
+			return fmt.Errorf("%s: empty range %s", name, spec)
+		}
+		s.isRange = true
+	} else {
+		for _, part := range strings.Split(spec, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			s.values = append(s.values, v)
+		}
+	}
+
+	*f = append(*f, s)
+	return nil
+}
+
+// tuneRow is the outcome of one setting over the sample of games
+type tuneRow struct {
+	Params           map[string]float64 `json:"params"`
+	Games            int                `json:"games"`
+	Wins             int                `json:"wins"`
+	WinRate          float64            `json:"win_rate"`
+	AvgMoves         float64            `json:"avg_moves"`
+	AvgCardsLeft     float64            `json:"avg_cards_left"`
+	IterationsPerSec float64            `json:"iterations_per_sec"`
+	RuntimeSec       float64            `json:"runtime_sec"`
+}
+
+// runTune plays a sample of deals under each combination of the varied
+// search parameters and writes a table of how each setting did
+func runTune(args []string) {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games_fixed.txt", "file of deals separated by blank lines")
+	deals := fs.String("deals", "", "deal numbers to play instead of the input file, e.g. 1-50")
+	variantName := fs.String("variant", "streets-and-alleys", "rules to play: "+streets.VariantNames())
+	sample := fs.Int("sample", 10, "number of deals to sample from the input (0 for all)")
+	mode := fs.String("mode", "grid", "grid tries every combination of -vary values; random draws -trials settings")
+	trials := fs.Int("trials", 10, "settings to try in random mode")
+	workers := fs.Int("workers", 1, "number of games to play in parallel")
+	seed := fs.Int64("seed", 1, "seed for sampling deals, drawing settings and the searches")
+	format := fs.String("format", "csv", "output format: csv or json")
+	outPath := fs.String("out", "", "file to write the table to (default standard output)")
+	var sweeps sweepFlags
+	fs.Var(&sweeps, "vary", "parameter to vary as name=list or name=min:max, repeatable; e.g. -vary exploration=1,1.414,1.8 -vary iterations=200,400")
+	search := addSearchFlags(fs, mcts.DefaultConfig())
+	fs.Parse(args)
+
+	base := search.config()
+	if len(sweeps) == 0 {
+		sweeps.Set("exploration=1,1.414,1.8,2.5")
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Printf("unknown format %q, expected csv or json\n", *format)
+		os.Exit(2)
+	}
+	if *workers < 1 {
+		fmt.Println("-workers must be at least 1")
+		os.Exit(2)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	settings, err := tuneSettings(sweeps, *mode, *trials, rng)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	games, err := loadGames(*inPath, *deals, []streets.Variant{parseVariantFlag(*variantName)})
+	if err != nil {
+		fmt.Printf("Error loading games: %v\n", err)
+		os.Exit(1)
+	}
+	games = sampleGames(games, *sample, rng)
+
+	out := io.Writer(os.Stdout)
+	if *outPath != "" {
+		outFile, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Printf("Error opening output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer outFile.Close()
+		out = outFile
+	}
+
+	// Progress goes to standard error so the table can be piped
+	fmt.Fprintf(os.Stderr, "Trying %d settings on %d games\n", len(settings), len(games))
+
+	var rows []tuneRow
+	for i, params := range settings {
+		cfg := base
+		for _, s := range sweeps {
+			tuneParams[s.name](&cfg, params[s.name])
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Setting %d %v: %v, skipped\n", i+1, params, err)
+			continue
+		}
+
+		row := tuneSetting(games, cfg, *seed, *workers)
+		row.Params = params
+		rows = append(rows, row)
+		fmt.Fprintf(os.Stderr, "Setting %d/%d %v: won %d of %d, %.1f moves on average, %.1fs\n",
+			i+1, len(settings), params, row.Wins, row.Games, row.AvgMoves, row.RuntimeSec)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(rows)
+	} else {
+		err = writeTuneCSV(out, sweeps, rows)
+	}
+	if err != nil {
+		fmt.Printf("Error writing results: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// tuneSettings lists the parameter values to try: every combination of
+// the sweeps' values in grid mode, or trials random draws
+func tuneSettings(sweeps []sweep, mode string, trials int, rng *rand.Rand) ([]map[string]float64, error) {
+	switch mode {
+	case "grid":
+		settings := []map[string]float64{{}}
+		for _, s := range sweeps {
+			if s.isRange {
+				return nil, fmt.Errorf("%s: grid mode needs a list of values, not a range", s.name)
+			}
+			var next []map[string]float64
+			for _, setting := range settings {
+				for _, value := range s.values {
+					combined := make(map[string]float64, len(setting)+1)
+					for name, v := range setting {
+						combined[name] = v
+					}
+					combined[s.name] = value
+					next = append(next, combined)
+				}
+			}
+			settings = next
+		}
+		return settings, nil
+
+	case "random":
+		settings := make([]map[string]float64, trials)
+		for i := range settings {
+			settings[i] = make(map[string]float64, len(sweeps))
+			for _, s := range sweeps {
+				settings[i][s.name] = s.draw(rng)
+			}
+		}
+		return settings, nil
+
+	default:
+		return nil, fmt.Errorf("unknown mode %q, expected grid or random", mode)
+	}
+}
+
+// sampleGames picks n of the games at random, keeping their order, or
+// returns them all when n is 0 or covers them
+func sampleGames(games []gameSpec, n int, rng *rand.Rand) []gameSpec {
+	if n <= 0 || n >= len(games) {
+		return games
+	}
+
+	picked := rng.Perm(len(games))[:n]
+	sort.Ints(picked)
+	sample := make([]gameSpec, n)
+	for i, gameNum := range picked {
+		sample[i] = games[gameNum]
+	}
+	return sample
+}
+
+// tuneSetting plays every game under cfg, each with the same search seed it
+// gets under every other setting, and totals the results
+func tuneSetting(games []gameSpec, cfg mcts.Config, seed int64, workers int) tuneRow {
+	start := time.Now()
+
+	jobs := make(chan gameSpec)
+	results := make(chan mcts.GameResult)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for spec := range jobs {
+				game, err := streets.Parse(spec.text)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", spec.name, err)
+					continue
+				}
+				rng := rand.New(rand.NewSource(gameSeed(seed, spec.key)))
+				results <- mcts.PlayGame(game, cfg, rng, nil)
+			}
+		}()
+	}
+	go func() {
+		for _, spec := range games {
+			jobs <- spec
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var row tuneRow
+	totalMoves, totalCardsLeft, totalIterations := 0, 0, 0
+	for result := range results {
+		row.Games++
+		if result.Won() {
+			row.Wins++
+		}
+		totalMoves += len(result.Moves)
+		totalCardsLeft += result.Final.CountCardsInRows()
+		totalIterations += result.Iterations
+	}
+
+	if row.Games > 0 {
+		row.WinRate = float64(row.Wins) / float64(row.Games)
+		row.AvgMoves = float64(totalMoves) / float64(row.Games)
+		row.AvgCardsLeft = float64(totalCardsLeft) / float64(row.Games)
+	}
+	row.RuntimeSec = time.Since(start).Seconds()
+	row.IterationsPerSec = float64(totalIterations) / row.RuntimeSec
+	return row
+}
+
+// writeTuneCSV writes one line per setting, with a column for each varied
+// parameter followed by the results
+func writeTuneCSV(out io.Writer, sweeps []sweep, rows []tuneRow) error {
+	w := csv.NewWriter(out)
+
+	header := make([]string, 0, len(sweeps)+7)
+	for _, s := range sweeps {
+		header = append(header, s.name)
+	}
+	header = append(header, "games", "wins", "win_rate", "avg_moves", "avg_cards_left", "iterations_per_sec", "runtime_sec")
+	w.Write(header)
+
+	for _, row := range rows {
+		record := make([]string, 0, len(header))
+		for _, s := range sweeps {
+			record = append(record, strconv.FormatFloat(row.Params[s.name], 'g', -1, 64))
+		}
+		record = append(record,
+			strconv.Itoa(row.Games),
+			strconv.Itoa(row.Wins),
+			strconv.FormatFloat(row.WinRate, 'f', 3, 64),
+			strconv.FormatFloat(row.AvgMoves, 'f', 1, 64),
+			strconv.FormatFloat(row.AvgCardsLeft, 'f', 1, 64),
+			strconv.FormatFloat(row.IterationsPerSec, 'f', 0, 64),
+			strconv.FormatFloat(row.RuntimeSec, 'f', 2, 64),
+		)
+		w.Write(record)
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSweepFlags(t *testing.T) {
+	tests := []struct {
+		value string
+		want  sweep
+		err   string // Substring of the error, empty for success
+	}{
+		{value: "exploration=1.4, 1.8", want: sweep{name: "exploration", values: []float64{1.4, 1.8}}},
+		{value: "iterations=100:400", want: sweep{name: "iterations", min: 100, max: 400, isRange: true}},
+		{value: "exploration", err: "expected name=values"},
+		{value: "speed=1", err: `unknown parameter "speed"`},
+		{value: "exploration=1.4,x", err: "exploration: strconv.ParseFloat"},
+		{value: "exploration=2:1", err: "empty range 2:1"},
+	}
+
+	for _, tt := range tests {
+		var sweeps sweepFlags
+		err := sweeps.Set(tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: error %v, want one containing %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(sweeps, sweepFlags{tt.want}) {
+			t.Errorf("%q parsed as %+v, want %+v", tt.value, sweeps, tt.want)
+		}
+	}
+}
+
+func TestTuneSettings(t *testing.T) {
+	sweeps := []sweep{
+		{name: "exploration", values: []float64{1, 2}},
+		{name: "iterations", values: []float64{100, 200, 300}},
+	}
+	grid, err := tuneSettings(sweeps, "grid", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grid) != 6 {
+		t.Fatalf("grid has %d settings, want 6", len(grid))
+	}
+	if want := map[string]float64{"exploration": 2, "iterations": 300}; !reflect.DeepEqual(grid[5], want) {
+		t.Errorf("last grid setting %v, want %v", grid[5], want)
+	}
+
+	if _, err := tuneSettings([]sweep{{name: "iterations", min: 1, max: 2, isRange: true}}, "grid", 0, nil); err == nil {
+		t.Error("grid mode accepted a range")
+	}
+	if _, err := tuneSettings(sweeps, "sideways", 0, nil); err == nil {
+		t.Error("unknown mode accepted")
+	}
+
+	// Random draws stay in range, and integer parameters stay whole
+	sweeps = []sweep{
+		{name: "exploration", min: 1, max: 2, isRange: true},
+		{name: "iterations", min: 100, max: 110, isRange: true},
+	}
+	random, err := tuneSettings(sweeps, "random", 50, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(random) != 50 {
+		t.Fatalf("%d random settings, want 50", len(random))
+	}
+	for _, setting := range random {
+		if e := setting["exploration"]; e < 1 || e > 2 {
+			t.Errorf("exploration %v out of range", e)
+		}
+		if i := setting["iterations"]; i < 100 || i > 110 || i != float64(int(i)) {
+			t.Errorf("iterations %v out of range or not whole", i)
+		}
+	}
+}
+
+func TestWriteTuneCSV(t *testing.T) {
+	sweeps := []sweep{{name: "exploration"}, {name: "iterations"}}
+	rows := []tuneRow{{
+		Params:           map[string]float64{"exploration": 1.8, "iterations": 400},
+		Games:            4,
+		Wins:             3,
+		WinRate:          0.75,
+		AvgMoves:         101.25,
+		AvgCardsLeft:     2.5,
+		IterationsPerSec: 350.4,
+		RuntimeSec:       12.345,
+	}}
+
+	var out strings.Builder
+	if err := writeTuneCSV(&out, sweeps, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "exploration,iterations,games,wins,win_rate,avg_moves,avg_cards_left,iterations_per_sec,runtime_sec\n" +
+		"1.8,400,4,3,0.750,101.2,2.5,350,12.35\n"
+	if out.String() != want {
+		t.Errorf("CSV\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+var (
+	// movePattern matches a single [from,to] pair
+	movePattern = regexp.MustCompile(`\[\s*(-?\d+)\s*,\s*(-?\d+)\s*\]`)
+	// jsGamePattern matches a game string in the solvedGames array
+	jsGamePattern = regexp.MustCompile(`game:\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// solution is a deal together with the moves claimed to win it
+type solution struct {
+	deal  string
+	moves []streets.Move
+}
+
+// runVerify replays every solution in the input file against its deal and
+// reports the first illegal move or the number of cards left at the end
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	inPath := fs.String("in", "winnable_games_moves.log", "solver log or solved-games.js file to check")
+	canonical := fs.Bool("canonical", false, "moves index normalized rows, as in logs written before Hash stopped normalizing")
+	fs.Parse(args)
+
+	content, err := os.ReadFile(*inPath)
+	if err != nil {
+		fmt.Printf("Error reading input file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var solutions []solution
+	if jsGamePattern.Match(content) {
+		solutions, err = parseJSSolutions(string(content))
+	} else {
+		solutions, err = parseLogSolutions(string(content))
+	}
+	if err != nil {
+		fmt.Printf("Error parsing %s: %v\n", *inPath, err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for i, sol := range solutions {
+		game, err := streets.Parse(sol.deal)
+		if err != nil {
+			fmt.Printf("Solution %d: error parsing deal: %v\n", i+1, err)
+			failed++
+			continue
+		}
+
+		moves := sol.moves
+		if *canonical {
+			physical, err := streets.PhysicalMoves(game, moves)
+			if err != nil {
+				fmt.Printf("Solution %d: %v\n", i+1, err)
+				failed++
+				continue
+			}
+			moves = physical
+		}
+
+		state, applied, err := streets.Replay(game, moves)
+		switch {
+		case err != nil:
+			fmt.Printf("Solution %d: %v after %d legal moves\n", i+1, err, applied)
+			failed++
+		case state.CountCardsInRows() != 0:
+			fmt.Printf("Solution %d: not a win, %d cards left in rows after %d moves\n", i+1, state.CountCardsInRows(), applied)
+			failed++
+		default:
+			fmt.Printf("Solution %d: ok, won in %d moves\n", i+1, applied)
+		}
+		if *canonical && err == nil {
+			fmt.Printf("  physical moves: %s\n", formatMoves(moves))
+		}
+	}
+
+	fmt.Printf("Checked %d solutions, %d failed\n", len(solutions), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseLogSolutions reads blocks of deal lines followed by a "moves: [...]"
+// line, as written by the solve and prove commands
+func parseLogSolutions(content string) ([]solution, error) {
+	var solutions []solution
+
+	for _, block := range splitGames(content) {
+		var deal []string
+		var moves []streets.Move
+		hasMoves := false
+
+		for _, line := range strings.Split(strings.TrimSpace(block), "\n") {
+			switch {
+			case strings.HasPrefix(line, "moves:"):
+				parsed, err := parseMoves(line)
+				if err != nil {
+					return nil, err
+				}
+				moves = parsed
+				hasMoves = true
+			case strings.HasPrefix(line, "foundations:"), strings.HasPrefix(line, "variant:"):
+				deal = append(deal, line)
+			case strings.Contains(line, ":"):
+				// Other annotations such as "result: unknown"
+			default:
+				deal = append(deal, line)
+			}
+		}
+
+		if hasMoves {
+			solutions = append(solutions, solution{deal: strings.Join(deal, "\n"), moves: moves})
+		}
+	}
+
+	return solutions, nil
+}
+
+// parseJSSolutions reads the solvedGames array from src/utils/solved-games.js
+func parseJSSolutions(content string) ([]solution, error) {
+	var solutions []solution
+	matches := jsGamePattern.FindAllStringSubmatchIndex(content, -1)
+
+	for i, match := range matches {
+		deal, err := strconv.Unquote(`"` + content[match[2]:match[3]] + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		// The moves run until the next game entry
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		moves, err := parseMoves(content[match[1]:end])
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		solutions = append(solutions, solution{deal: deal, moves: moves})
+	}
+
+	return solutions, nil
+}
+
+// parseMoves extracts every [from,to] pair from s
+func parseMoves(s string) ([]streets.Move, error) {
+	var moves []streets.Move
+	for _, pair := range movePattern.FindAllStringSubmatch(s, -1) {
+		from, err := strconv.Atoi(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := strconv.Atoi(pair[2])
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, streets.Move{From: from, To: to})
+	}
+	return moves, nil
+}
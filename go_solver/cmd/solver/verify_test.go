@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/exhaustive"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestParseLogSolutions(t *testing.T) {
+	log := "deal: 12\nvariant: citadel\nAH 2H\nfoundations: AS\nmoves: [[0,-1],[1,-1]]\n\n" +
+		"2C 3C\nresult: unknown\n\n" +
+		"KD\r\nmoves: []\r\n"
+
+	got, err := parseLogSolutions(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []solution{
+		{deal: "variant: citadel\nAH 2H\nfoundations: AS", moves: []streets.Move{{From: 0, To: -1}, {From: 1, To: -1}}},
+		{deal: "KD"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseJSSolutions(t *testing.T) {
+	js := `export const solvedGames = [
+  {
+    game: "AH 2H\n3H \"x\"",
+    moves: [
+      [0, -1],
+      [1, -1],
+    ],
+  },
+  { game: "KD", moves: [[2,3]] },
+];`
+
+	got, err := parseJSSolutions(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []solution{
+		{deal: "AH 2H\n3H \"x\"", moves: []streets.Move{{From: 0, To: -1}, {From: 1, To: -1}}},
+		{deal: "KD", moves: []streets.Move{{From: 2, To: 3}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoggedSolutionVerifies(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(151)
+	result := exhaustive.Solve(game, 100000, streets.AutoplayOff)
+	if result.Status != exhaustive.Winnable {
+		t.Fatalf("deal 151 is %s, want winnable", result.Status)
+	}
+
+	spec := gameSpec{name: "deal 151", dealNumber: 151, text: game.ToString()}
+	solutions, err := parseLogSolutions(spec.logHeader() + "\nmoves: " + formatMoves(result.Moves) + "\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solutions) != 1 {
+		t.Fatalf("parsed %d solutions, want 1", len(solutions))
+	}
+
+	parsed, err := streets.Parse(solutions[0].deal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	final, i, err := streets.Replay(parsed, solutions[0].moves)
+	if err != nil {
+		t.Fatalf("logged line fails at move %d: %v", i, err)
+	}
+	if left := final.CountCardsInRows(); left != 0 {
+		t.Errorf("logged line leaves %d cards in the rows", left)
+	}
+}
@@ -0,0 +1,167 @@
+//go:build js && wasm
+
+// Command wasm exposes the solver to the browser when built with
+//
+//	GOOS=js GOARCH=wasm go build -o ../public/solver.wasm ./cmd/wasm
+//
+// (or npm run build:wasm). It sets a global goSolver object whose functions
+// take and return JSON strings, using the api package formats:
+//
+//	goSolver.parse(deal)             -> position
+//	goSolver.legalMoves(position)    -> [[from,to],...]
+//	goSolver.apply(position, from, to) -> position
+//	goSolver.hint(position)          -> hint response
+//	goSolver.health(position)        -> health response
+//	goSolver.solve(position)         -> solve response
+//
+// Failures come back as {"error": "..."}. Hint and solve run on the calling
+// thread, so call them from a worker to keep the page responsive.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"syscall/js"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/api"
+	"github.com/brettlyne/cards/go_solver/mcts"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// The exhaustive solver's transposition table takes about 250 bytes a
+// position here, and WebAssembly memory never shrinks once grown, so these
+// budgets keep a solve to around 50 MB
+var (
+	solveOpts = api.Options{MaxStates: 200000, Autoplay: streets.AutoplaySafe}
+	hintOpts  = api.Options{MaxStates: 20000, Iterations: mcts.DefaultConfig().Iterations, Autoplay: streets.AutoplaySafe}
+)
+
+func main() {
+	js.Global().Set("goSolver", js.ValueOf(map[string]any{
+		"parse":      export(parse),
+		"legalMoves": export(legalMoves),
+		"apply":      export(apply),
+		"hint":       export(hint),
+		"health":     export(health),
+		"solve":      export(solve),
+	}))
+
+	// Keep the exported functions alive
+	select {}
+}
+
+// export wraps a Go function as a JavaScript function returning JSON
+func export(fn func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		result, err := fn(args)
+		if err != nil {
+			result = map[string]string{"error": err.Error()}
+		}
+
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Sprintf(`{"error": %q}`, err.Error())
+		}
+		return string(encoded)
+	})
+}
+
+// gameArg parses the JSON position passed as the first argument
+func gameArg(args []js.Value) (streets.StreetsGame, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return streets.StreetsGame{}, fmt.Errorf("expected a JSON position")
+	}
+
+	var position api.Position
+	if err := json.Unmarshal([]byte(args[0].String()), &position); err != nil {
+		return streets.StreetsGame{}, err
+	}
+	return position.Game()
+}
+
+// intArg returns args[i] as an int, or an error naming the argument if it
+// isn't a whole number
+func intArg(args []js.Value, i int, name string) (int, error) {
+	if len(args) <= i || args[i].Type() != js.TypeNumber {
+		return 0, fmt.Errorf("expected %s to be a number", name)
+	}
+	value := args[i].Float()
+	if value != math.Trunc(value) || math.Abs(value) > math.MaxInt32 {
+		return 0, fmt.Errorf("expected %s to be a row number, got %v", name, value)
+	}
+	return int(value), nil
+}
+
+func parse(args []js.Value) (any, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return nil, fmt.Errorf("expected deal text")
+	}
+
+	game, err := streets.Parse(args[0].String())
+	if err != nil {
+		return nil, err
+	}
+	return api.FromGame(game), nil
+}
+
+func legalMoves(args []js.Value) (any, error) {
+	game, err := gameArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return api.LegalMoves(game), nil
+}
+
+func apply(args []js.Value) (any, error) {
+	game, err := gameArg(args)
+	if err != nil {
+		return nil, err
+	}
+	from, err := intArg(args, 1, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := intArg(args, 2, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	move := streets.Move{From: from, To: to}
+	if !game.IsLegal(move) {
+		return nil, fmt.Errorf("illegal move: %s", move)
+	}
+
+	nextState, err := game.Apply(move)
+	if err != nil {
+		return nil, err
+	}
+	return api.FromGame(nextState), nil
+}
+
+func hint(args []js.Value) (any, error) {
+	game, err := gameArg(args)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return api.Hint(game, hintOpts, rng), nil
+}
+
+func health(args []js.Value) (any, error) {
+	game, err := gameArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return api.Health(game, streets.DefaultEvaluator()), nil
+}
+
+func solve(args []js.Value) (any, error) {
+	game, err := gameArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return api.Solve(game, solveOpts), nil
+}
@@ -0,0 +1,71 @@
+//go:build js && wasm
+
+// These tests run under Node with
+//
+//	GOOS=js GOARCH=wasm go test -exec "$(go env GOROOT)/lib/wasm/go_js_wasm_exec" ./cmd/wasm
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/api"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestApplyChecksArguments(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(1)
+	position, err := json.Marshal(api.FromGame(game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	move := game.LegalMoves()[0]
+
+	tests := []struct {
+		name string
+		args []any
+		want string // Substring of the error, empty for success
+	}{
+		{"legal move", []any{string(position), move.From, move.To}, ""},
+		{"missing to", []any{string(position), move.From}, "expected to to be a number"},
+		{"string from", []any{string(position), "0", move.To}, "expected from to be a number"},
+		{"null to", []any{string(position), move.From, nil}, "expected to to be a number"},
+		{"fractional from", []any{string(position), 0.5, move.To}, "expected from to be a row number"},
+		{"huge to", []any{string(position), move.From, 1e12}, "expected to to be a row number"},
+		{"no such row", []any{string(position), 42, move.To}, "illegal move"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := make([]js.Value, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = js.ValueOf(arg)
+			}
+
+			_, err := apply(args)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportReturnsErrorObject(t *testing.T) {
+	fn := export(apply)
+	defer fn.Release()
+
+	var result map[string]string
+	if err := json.Unmarshal([]byte(fn.Invoke("{}", "x", 1).String()), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["error"] == "" {
+		t.Errorf("got %v, want an error object", result)
+	}
+}
@@ -0,0 +1,177 @@
+// Package exhaustive implements a complete depth-first solver for Streets and
+// Alleys that either finds a winning line or proves that none exists.
+package exhaustive
+
+import "github.com/brettlyne/cards/go_solver/streets"
+
+// Status is the outcome of an exhaustive search
+type Status int
+
+const (
+	// Unknown means the search hit its state limit before finishing
+	Unknown Status = iota
+	// Winnable means a full solution was found
+	Winnable
+	// Unwinnable means every reachable position was searched without a win
+	Unwinnable
+)
+
+// String returns a human-readable name for the status
+func (s Status) String() string {
+	switch s {
+	case Winnable:
+		return "winnable"
+	case Unwinnable:
+		return "unwinnable"
+	default:
+		return "unknown"
+	}
+}
+
+// Result holds the outcome of Solve
+type Result struct {
+	Status Status
+	Moves  []streets.Move // Winning line from the starting position when Status is Winnable
+	States int            // Number of distinct positions visited
+}
+
+// frame is one position on the depth-first search stack. Frames share a
+// single working state, so each keeps only what it needs to undo the move
+// that reached it.
+type frame struct {
+	moves       []streets.Move // Moves still to try from this position
+	steps       []step         // The move that reached this position and its automatic moves
+	foundations [4]int         // Foundations before those moves
+	base        int            // Base rank before those moves
+	pathSize    int            // Length of the path that reaches this position
+}
+
+// step is a move that was played and the card it moved
+type step struct {
+	move streets.Move
+	card streets.Card
+}
+
+// Solve runs a depth-first search over every position reachable from game.
+// Positions are deduplicated with a transposition table keyed on Hash(), so
+// the search terminates and an exhausted search is a proof that the deal is
+// lost. maxStates caps the size of the table; zero or less means no limit.
+// Every move is followed by the automatic foundation moves allowed by
+// autoplay, and those moves are listed in the winning line too.
+func Solve(game streets.StreetsGame, maxStates int, autoplay streets.Autoplay) Result {
+	state, path := game.ApplyAutoplay(autoplay) // Moves from the root to the top of the stack
+	seen := map[string]bool{state.Hash(): true}
+	stack := []frame{{moves: orderedMoves(state), pathSize: len(path)}}
+
+	if state.CountCardsInRows() == 0 {
+		return Result{Status: Winnable, Moves: path, States: len(seen)}
+	}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+
+		// Backtrack once every move from this position has been tried
+		if len(top.moves) == 0 {
+			top.undo(&state)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		move := top.moves[0]
+		top.moves = top.moves[1:]
+
+		next := frame{foundations: state.Foundations, base: state.Base}
+		nextState, ok := next.play(state, move, autoplay)
+		if !ok {
+			continue
+		}
+
+		key := nextState.Hash()
+		if seen[key] {
+			continue
+		}
+		if maxStates > 0 && len(seen) >= maxStates {
+			return Result{Status: Unknown, States: len(seen)}
+		}
+		seen[key] = true
+
+		path = path[:top.pathSize]
+		for _, s := range next.steps {
+			path = append(path, s.move)
+		}
+		if nextState.CountCardsInRows() == 0 {
+			return Result{Status: Winnable, Moves: path, States: len(seen)}
+		}
+
+		state = nextState
+		next.moves = orderedMoves(state)
+		next.pathSize = len(path)
+		stack = append(stack, next)
+	}
+
+	return Result{Status: Unwinnable, States: len(seen)}
+}
+
+// play plays move from state followed by its automatic moves, recording in
+// f the cards they moved, and returns the position reached
+func (f *frame) play(state streets.StreetsGame, move streets.Move, autoplay streets.Autoplay) (streets.StreetsGame, bool) {
+	card, _ := state.LastCard(move.From)
+	moved, err := state.Apply(move)
+	if err != nil {
+		return state, false
+	}
+	f.steps = append(f.steps, step{move: move, card: card})
+
+	nextState, autoMoves := moved.ApplyAutoplay(autoplay)
+	for _, auto := range autoMoves {
+		// Automatic moves only take cards off the ends of rows, in order
+		card, col := moved.LastCard(auto.From)
+		moved.Rows[auto.From][col] = streets.Card{}
+		f.steps = append(f.steps, step{move: auto, card: card})
+	}
+	return nextState, true
+}
+
+// undo takes state back to the position before f's moves were played
+func (f *frame) undo(state *streets.StreetsGame) {
+	for i := len(f.steps) - 1; i >= 0; i-- {
+		s := f.steps[i]
+		if s.move.To != streets.Foundation {
+			_, col := state.LastCard(s.move.To)
+			state.Rows[s.move.To][col] = streets.Card{}
+		}
+		_, col := state.LastCard(s.move.From)
+		state.Rows[s.move.From][col+1] = s.card
+	}
+	state.Foundations = f.foundations
+	state.Base = f.base
+}
+
+// orderedMoves returns the legal moves with the most promising first:
+// foundation moves, then builds onto other cards, then moves into an empty row
+func orderedMoves(state streets.StreetsGame) []streets.Move {
+	legalMoves := state.LegalMoves()
+	ordered := make([]streets.Move, 0, len(legalMoves))
+
+	for _, move := range legalMoves {
+		if move.To == streets.Foundation {
+			ordered = append(ordered, move)
+		}
+	}
+	for _, move := range legalMoves {
+		if move.To != streets.Foundation {
+			if _, col := state.LastCard(move.To); col != -1 {
+				ordered = append(ordered, move)
+			}
+		}
+	}
+	for _, move := range legalMoves {
+		if move.To != streets.Foundation {
+			if _, col := state.LastCard(move.To); col == -1 {
+				ordered = append(ordered, move)
+			}
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,64 @@
+package exhaustive
+
+import (
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// deal returns the numbered Streets and Alleys deal
+func deal(number int64) streets.StreetsGame {
+	g := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	g.Deal(number)
+	return g
+}
+
+func TestWinningLineReplays(t *testing.T) {
+	for _, autoplay := range []streets.Autoplay{streets.AutoplayOff, streets.AutoplayAces, streets.AutoplaySafe} {
+		game := deal(151)
+		result := Solve(game, 100000, autoplay)
+		if result.Status != Winnable {
+			t.Fatalf("autoplay %s: deal 151 is %s, want winnable", autoplay, result.Status)
+		}
+
+		final, i, err := streets.Replay(game, result.Moves)
+		if err != nil {
+			t.Fatalf("autoplay %s: winning line fails at move %d: %v", autoplay, i, err)
+		}
+		if left := final.CountCardsInRows(); left != 0 {
+			t.Errorf("autoplay %s: winning line leaves %d cards in the rows", autoplay, left)
+		}
+	}
+}
+
+func TestUnwinnableWithinBudget(t *testing.T) {
+	result := Solve(deal(153), 100000, streets.AutoplayOff)
+	if result.Status != Unwinnable {
+		t.Fatalf("deal 153 is %s, want unwinnable", result.Status)
+	}
+	if result.States == 0 || result.States >= 100000 {
+		t.Errorf("searched %d states, want a finished search under the budget", result.States)
+	}
+	if len(result.Moves) != 0 {
+		t.Errorf("unwinnable result has %d moves", len(result.Moves))
+	}
+}
+
+func TestUnknownAtBudget(t *testing.T) {
+	result := Solve(deal(163), 1000, streets.AutoplayOff)
+	if result.Status != Unknown {
+		t.Fatalf("deal 163 with 1000 states is %s, want unknown", result.Status)
+	}
+	if result.States != 1000 {
+		t.Errorf("searched %d states, want the budget of 1000", result.States)
+	}
+}
+
+func TestSolveDoesNotChangeGame(t *testing.T) {
+	game := deal(170)
+	before := game
+	Solve(game, 5000, streets.AutoplaySafe)
+	if game != before {
+		t.Error("Solve modified the game it was given")
+	}
+}
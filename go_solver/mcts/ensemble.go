@@ -0,0 +1,60 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// searchEnsemble searches cfg.Ensemble independent trees from state at the
+// same time, each drawing from its own generator seeded from rng, and merges
+// their root children into a single root so that BestMove picks the move
+// with the most visits across all of them. It also reports whether the
+// trees' own best moves differed.
+func searchEnsemble(state streets.StreetsGame, cfg Config, rng *rand.Rand, deadline time.Time) (*Node, bool) {
+	member := cfg
+	member.Ensemble = 1
+
+	trees := make([]*Node, cfg.Ensemble)
+	var wg sync.WaitGroup
+	for i := range trees {
+		treeRng := rand.New(rand.NewSource(rng.Int63()))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trees[i], _ = search(nil, state, member, treeRng, deadline)
+		}(i)
+	}
+	wg.Wait()
+
+	// Sum each move's statistics over the trees; the merged children are
+	// leaves, since only the root's statistics take part in the vote
+	rootNode := NewNode(state.Hash())
+	moveIndex := make(map[streets.Move]int) // Position of each move in rootNode.Moves
+	firstBest, _ := trees[0].BestMove()
+	disagreed := false
+	for _, tree := range trees {
+		if best, _ := tree.BestMove(); best != firstBest {
+			disagreed = true
+		}
+
+		rootNode.Visits += tree.Visits
+		rootNode.TotalReward += tree.TotalReward
+		for i, move := range tree.Moves {
+			child := tree.Children[move]
+			index, exists := moveIndex[move]
+			if !exists {
+				index = len(rootNode.Moves)
+				moveIndex[move] = index
+				rootNode.addChild(move, tree.keys[i], NewNode(child.GameStateHash))
+			}
+			merged := rootNode.Children[move]
+			merged.Visits += child.Visits
+			merged.TotalReward += child.TotalReward
+			rootNode.edgeVisits[index] += tree.edgeVisits[i]
+		}
+	}
+	return rootNode, disagreed
+}
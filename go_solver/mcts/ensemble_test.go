@@ -0,0 +1,54 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestEnsembleSumsItsTrees(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 60
+	cfg.Ensemble = 3
+	root, disagreed := searchEnsemble(game, cfg, rand.New(rand.NewSource(1)), time.Time{})
+
+	// Search the same trees one at a time, seeded the way the ensemble seeds them
+	member := cfg
+	member.Ensemble = 1
+	seeds := rand.New(rand.NewSource(1))
+	edgeVisits := make(map[streets.Move]int)
+	childVisits := make(map[streets.Move]int)
+	var bests []streets.Move
+	for i := 0; i < cfg.Ensemble; i++ {
+		tree := Search(game, member, rand.New(rand.NewSource(seeds.Int63())), time.Time{})
+		for j, move := range tree.Moves {
+			edgeVisits[move] += tree.edgeVisits[j]
+			childVisits[move] += tree.Children[move].Visits
+		}
+		best, _ := tree.BestMove()
+		bests = append(bests, best)
+	}
+
+	if want := cfg.Ensemble * cfg.Iterations; root.Visits != want {
+		t.Errorf("%d root visits, want %d", root.Visits, want)
+	}
+	if len(root.Moves) != len(edgeVisits) {
+		t.Errorf("merged root has %d moves, the trees %d", len(root.Moves), len(edgeVisits))
+	}
+	for i, move := range root.Moves {
+		if root.edgeVisits[i] != edgeVisits[move] || root.Children[move].Visits != childVisits[move] {
+			t.Errorf("%v: %d visits to a child with %d, want %d and %d",
+				move, root.edgeVisits[i], root.Children[move].Visits, edgeVisits[move], childVisits[move])
+		}
+	}
+
+	wantDisagreed := bests[1] != bests[0] || bests[2] != bests[0]
+	if disagreed != wantDisagreed {
+		t.Errorf("disagreed = %v with best moves %v", disagreed, bests)
+	}
+}
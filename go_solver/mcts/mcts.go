@@ -0,0 +1,554 @@
+// Package mcts implements a Monte Carlo Tree Search solver for Streets and Alleys.
+package mcts
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// Config holds the search parameters and budgets
+type Config struct {
+	Iterations          int               // MCTS iterations to run per move, 0 for no limit when MoveTime is set
+	MaxRolloutLength    int               // Maximum number of moves in a simulation
+	RolloutCutoff       int               // Moves after which a simulation stops and Evaluator scores where it got to; 0 plays on to MaxRolloutLength
+	ExplorationConstant float64           // Weight of the exploration bonus in the UCT formula
+	RewardPower         float64           // Rollout reward is the fraction of cards up, or the evaluator's score, raised to this power; above 1 favours near wins
+	LimitBonus          float64           // Cards credited to a rollout that reaches MaxRolloutLength without getting stuck, when scored by the cards up
+	MaxMoves            int               // Moves to play in a game before giving up
+	MoveTime            time.Duration     // Search time per move, 0 for no limit
+	GameTime            time.Duration     // Total time for a game, 0 for no limit
+	Autoplay            streets.Autoplay  // Foundation moves to play automatically after each move
+	Threads             int               // Goroutines running iterations on the same tree; 1 searches serially
+	Ensemble            int               // Independent trees searched per move, voting with their root visits; 1 searches one tree
+	Transpositions      bool              // Share one node between every move order that reaches a position
+	ReuseTree           bool              // Start each move's search from the subtree of the move played, rather than a new tree; ignored by an ensemble
+	RAVEEquivalence     float64           // Visits at which a move's own average and its all-moves-as-first average count equally; 0 turns RAVE off
+	Rollout             RolloutPolicy     // How simulations choose their moves
+	RolloutEpsilon      float64           // Chance a heuristic rollout plays a random move instead of its best one
+	RolloutTemperature  float64           // If positive, heuristic rollouts draw moves by softmax of their scores at this temperature instead
+	Evaluator           streets.Evaluator // Scores positions for EvalRollouts, RolloutCutoff and LeafWeight
+	EvalRollouts        bool              // Score where rollouts end with Evaluator rather than by the cards up; implied by RolloutCutoff and LeafWeight
+	LeafWeight          float64           // Weight of Evaluator's score of each new leaf against its rollout; 1 skips rollouts
+}
+
+// DefaultConfig returns the parameters the solver has been tuned with.
+// Rollouts play out to MaxRolloutLength and are scored by the cards up, with
+// LimitBonus 1, rather than by Evaluator: over 8 deals at 100 iterations a
+// move, the built-in evaluator and cutoffs of 20 or 50 moves left as many
+// cards in the rows or more, so the evaluator stays opt-in.
+func DefaultConfig() Config {
+	return Config{
+		Iterations:       400,
+		MaxRolloutLength: 150,
+		// ExplorationConstant: 1.414, // sqrt(2)
+		// √2 is derived from the multi-armed bandit problem
+		ExplorationConstant: 1.8,
+		RewardPower:         1,
+		LimitBonus:          1,
+		MaxMoves:            250,
+		Autoplay:            streets.AutoplaySafe,
+		Threads:             1,
+		Ensemble:            1,
+		Rollout:             RolloutRandom,
+		RolloutEpsilon:      0.1,
+		Evaluator:           streets.DefaultEvaluator(),
+	}
+}
+
+// Validate reports settings the search can't run with
+func (c Config) Validate() error {
+	switch {
+	case c.Iterations < 0:
+		return fmt.Errorf("iterations must not be negative")
+	case c.Iterations == 0 && c.MoveTime <= 0 && c.GameTime <= 0:
+		return fmt.Errorf("need an iteration count or a time limit per move or game")
+	case c.MaxRolloutLength < 1:
+		return fmt.Errorf("rollout length must be at least 1")
+	case c.RolloutCutoff < 0:
+		return fmt.Errorf("rollout cutoff must not be negative")
+	case c.ExplorationConstant < 0:
+		return fmt.Errorf("exploration constant must not be negative")
+	case c.RewardPower <= 0:
+		return fmt.Errorf("reward power must be positive")
+	case c.LimitBonus < 0:
+		return fmt.Errorf("limit bonus must not be negative")
+	case c.MaxMoves < 1:
+		return fmt.Errorf("max moves must be at least 1")
+	case c.Threads < 1:
+		return fmt.Errorf("threads must be at least 1")
+	case c.Ensemble < 1:
+		return fmt.Errorf("ensemble size must be at least 1")
+	case c.RAVEEquivalence < 0:
+		return fmt.Errorf("RAVE equivalence must not be negative")
+	case c.RolloutEpsilon < 0 || c.RolloutEpsilon > 1:
+		return fmt.Errorf("rollout epsilon must be between 0 and 1")
+	case c.RolloutTemperature < 0:
+		return fmt.Errorf("rollout temperature must not be negative")
+	case c.LeafWeight < 0 || c.LeafWeight > 1:
+		return fmt.Errorf("leaf weight must be between 0 and 1")
+	}
+	return nil
+}
+
+// Node represents a node in the Monte Carlo Tree Search. With
+// Config.Transpositions a node can be the child of several nodes, making the
+// search a graph. Nodes don't point back to their parents, so a subtree
+// kept for the next move's search doesn't keep the rest of the old tree
+// alive. Paths to a node may leave its rows in different orders, so in a
+// graph its Moves, the root's included, are between canonical row positions
+// (see streets.RowOrder).
+type Node struct {
+	GameStateHash string                 // Hash of the game state this node represents
+	Children      map[streets.Move]*Node // Map of moves to child nodes
+	Moves         []streets.Move         // Child moves in the order they were added, for deterministic iteration
+	Visits        int                    // Number of times this node has been visited
+	TotalReward   float64                // Sum of rewards from all visits to this node
+	edgeVisits    []int                  // Visits made from this node through each of Moves
+	keys          []moveKey              // What each of Moves does, wherever it is played
+	amaf          map[moveKey]amafStats  // All-moves-as-first statistics of moves played at or after this node
+	inFlight      int                    // Iterations that have passed through this node but not yet backpropagated
+	table         map[string]*Node       // On the root of a transposition search, every node by its hash
+}
+
+// NewNode creates a new Node with initialized fields
+func NewNode(gameStateHash string) *Node {
+	return &Node{
+		GameStateHash: gameStateHash,
+		Children:      make(map[streets.Move]*Node),
+		Visits:        0,
+		TotalReward:   0,
+	}
+}
+
+// selectChild uses UCT formula to select the most promising child node,
+// returning its index in n.Moves. Children whose hash is in onPath are
+// skipped, so a search graph can't be walked round a cycle; the index is -1
+// if every child was skipped.
+func (n *Node) selectChild(cfg Config, onPath map[string]bool) (*Node, streets.Move, int) {
+	bestScore := -1.0
+	var bestChild *Node
+	var bestMove streets.Move
+	bestIndex := -1
+
+	for i, move := range n.Moves {
+		child := n.Children[move]
+		if onPath[child.GameStateHash] {
+			continue
+		}
+
+		// Iterations still in flight count as visits that scored nothing
+		// (a virtual loss), steering parallel searches apart
+		visits := n.edgeVisits[i] + child.inFlight
+
+		// UCT formula: average reward + exploration bonus. The average is
+		// the child's own, shared by every move order that reaches it, while
+		// the bonus depends on how often this move was tried from here.
+		var exploitation float64
+		if amaf, ok := n.amaf[n.keys[i]]; ok && cfg.RAVEEquivalence > 0 {
+			// Blend in how the same move did whenever it was played later
+			// on, trusting it less as the move's own visits grow
+			amafValue := amaf.reward / float64(amaf.visits)
+			if visits == 0 {
+				exploitation, visits = amafValue, 1
+			} else {
+				beta := math.Sqrt(cfg.RAVEEquivalence / (3*float64(visits) + cfg.RAVEEquivalence))
+				exploitation = (1-beta)*child.TotalReward/float64(child.Visits+child.inFlight) + beta*amafValue
+			}
+		} else if visits == 0 {
+			return child, move, i
+		} else {
+			exploitation = child.TotalReward / float64(child.Visits+child.inFlight)
+		}
+		exploration := cfg.ExplorationConstant *
+			math.Sqrt(math.Log(float64(n.Visits))/float64(visits))
+		score := exploitation + exploration
+
+		if score > bestScore {
+			bestScore = score
+			bestChild = child
+			bestMove = move
+			bestIndex = i
+		}
+	}
+
+	return bestChild, bestMove, bestIndex
+}
+
+// children are the legal moves in a position, the hashes of the states they
+// lead to and what each move does
+type children struct {
+	moves  []streets.Move
+	hashes []string
+	keys   []moveKey
+}
+
+// expansion works out the children of gameState, ready for expand, with
+// their moves in canonical form if graph is set. It doesn't touch the tree,
+// so parallel searches can call it without holding the lock.
+func expansion(gameState streets.StreetsGame, autoplay streets.Autoplay, graph bool) children {
+	legalMoves := gameState.LegalMoves()
+	c := children{
+		moves:  make([]streets.Move, len(legalMoves)),
+		hashes: make([]string, len(legalMoves)),
+		keys:   make([]moveKey, len(legalMoves)),
+	}
+	var order streets.RowOrder
+	if graph {
+		order = gameState.CanonicalOrder()
+	}
+	for i, move := range legalMoves {
+		c.moves[i] = move
+		if graph {
+			c.moves[i] = order.ToCanonical(move)
+		}
+		c.hashes[i] = applyMove(gameState, move, autoplay).Hash()
+		c.keys[i] = keyOf(&gameState, move)
+	}
+	return c
+}
+
+// physicalMove returns a move taken from the node for state as a move
+// between state's rows, converting it from canonical form if graph is set
+func physicalMove(state streets.StreetsGame, move streets.Move, graph bool) streets.Move {
+	if !graph {
+		return move
+	}
+	return state.CanonicalOrder().ToPhysical(move)
+}
+
+// expand adds a child node for each of the moves from expansion. A position
+// already in table, if not nil, becomes a child as it is; new nodes are
+// added to it.
+func (n *Node) expand(c children, table map[string]*Node) {
+	for i, move := range c.moves {
+		if _, exists := n.Children[move]; exists {
+			continue
+		}
+
+		child := table[c.hashes[i]]
+		if child == nil {
+			child = NewNode(c.hashes[i])
+			if table != nil {
+				table[c.hashes[i]] = child
+			}
+		}
+		n.addChild(move, c.keys[i], child)
+	}
+}
+
+// addChild links child to n as the result of move
+func (n *Node) addChild(move streets.Move, key moveKey, child *Node) {
+	n.Children[move] = child
+	n.Moves = append(n.Moves, move)
+	n.edgeVisits = append(n.edgeVisits, 0)
+	n.keys = append(n.keys, key)
+}
+
+// backpropagate updates the statistics of the nodes an iteration passed
+// through, and of the moves it took between them, ending the iteration that
+// entered each node. edges[i] is the index of the move from nodes[i] to
+// nodes[i+1].
+func backpropagate(nodes []*Node, edges []int, reward float64) {
+	for i, node := range nodes {
+		node.Visits++
+		node.TotalReward += reward
+		node.inFlight--
+		if i < len(edges) {
+			node.edgeVisits[edges[i]]++
+		}
+	}
+}
+
+// applyMove plays move followed by any automatic foundation moves it allows
+func applyMove(state streets.StreetsGame, move streets.Move, autoplay streets.Autoplay) streets.StreetsGame {
+	nextState, _ := state.Apply(move)
+	nextState, _ = nextState.ApplyAutoplay(autoplay)
+	return nextState
+}
+
+// promote detaches the child reached by move to be the root of the next
+// search, keeping its statistics. A transposition table is cut down to the
+// nodes still reachable from it.
+func (n *Node) promote(move streets.Move) *Node {
+	child := n.Children[move]
+	if child == nil {
+		return nil
+	}
+
+	if n.table != nil {
+		child.table = map[string]*Node{child.GameStateHash: child}
+		queue := []*Node{child}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			for _, next := range node.Children {
+				if _, seen := child.table[next.GameStateHash]; !seen {
+					child.table[next.GameStateHash] = next
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return child
+}
+
+// noLock is the lock serial searches pass to run, since nothing else
+// touches their tree
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+// Run performs one iteration of the MCTS algorithm
+// Every move in the tree and in the rollout is followed by the automatic
+// foundation moves allowed by cfg.Autoplay. Rollouts draw from rng, so a
+// search is reproducible given the same generator state.
+func Run(rootState streets.StreetsGame, rootNode *Node, cfg Config, rng *rand.Rand) {
+	run(rootState, rootNode, cfg, rng, noLock{})
+}
+
+// run performs one iteration, holding tree only while it reads or updates
+// node statistics and children, so that parallel searches can play out
+// positions and compute expansions at the same time
+func run(rootState streets.StreetsGame, rootNode *Node, cfg Config, rng *rand.Rand, tree sync.Locker) {
+	autoplay := cfg.Autoplay
+
+	// Selection phase - traverse tree until we reach a leaf node, marking
+	// each node on the way as having an iteration in flight
+	tree.Lock()
+	if cfg.Transpositions && rootNode.table == nil {
+		rootNode.table = map[string]*Node{rootNode.GameStateHash: rootNode}
+	}
+	currentNode := rootNode
+	currentNode.inFlight++
+	nodes := []*Node{currentNode}
+	var edges []int
+	var path []streets.Move
+
+	// In a graph, keep off the positions already on the path
+	graph := rootNode.table != nil
+	var onPath map[string]bool
+	if graph {
+		onPath = map[string]bool{currentNode.GameStateHash: true}
+	}
+
+	for len(currentNode.Children) > 0 { // while there are children to visit
+		next, move, edge := currentNode.selectChild(cfg, onPath)
+		if edge == -1 {
+			break
+		}
+		currentNode = next
+		currentNode.inFlight++
+		nodes = append(nodes, currentNode)
+		edges = append(edges, edge)
+		path = append(path, move)
+		if onPath != nil {
+			onPath[currentNode.GameStateHash] = true
+		}
+	}
+	visited := currentNode.Visits > 0
+	tree.Unlock()
+
+	// Replay the path, tracking only states in our actual path through the tree
+	currentState := rootState.Clone()
+	pathStates := make(map[string]bool)
+	pathStates[currentState.Hash()] = true
+	for _, move := range path {
+		currentState = applyMove(currentState, physicalMove(currentState, move, graph), autoplay)
+		pathStates[currentState.Hash()] = true
+	}
+
+	// Expansion phase - if node has been visited before, expand it
+	if visited {
+		expanded := expansion(currentState, autoplay, graph)
+		tree.Lock()
+		currentNode.expand(expanded, rootNode.table)
+		next, move, edge := currentNode.selectChild(cfg, onPath)
+		descended := edge != -1
+		if descended {
+			currentNode = next
+			currentNode.inFlight++
+			nodes = append(nodes, currentNode)
+			edges = append(edges, edge)
+		}
+		tree.Unlock()
+
+		if descended {
+			currentState = applyMove(currentState, physicalMove(currentState, move, graph), autoplay)
+			pathStates[currentState.Hash()] = true
+		}
+	}
+
+	// Simulation phase - now each simulation starts fresh with just the path
+	// states, and the evaluator's view of the leaf is mixed in if wanted
+	var reward float64
+	var rolloutKeys []moveKey
+	if cfg.LeafWeight < 1 {
+		reward, _, rolloutKeys = simulate(currentState, pathStates, cfg, rng)
+	}
+	if cfg.LeafWeight > 0 {
+		reward = cfg.LeafWeight*cfg.reward(&currentState, 0) + (1-cfg.LeafWeight)*reward
+	}
+
+	// Backpropagation phase
+	tree.Lock()
+	backpropagate(nodes, edges, reward)
+	if cfg.RAVEEquivalence > 0 {
+		updateAMAF(nodes, edges, rolloutKeys, reward)
+	}
+	tree.Unlock()
+}
+
+// Simulate performs a playout from the given game state, of at most
+// cfg.MaxRolloutLength moves chosen by cfg.Rollout, or cfg.RolloutCutoff
+// moves, and scores where it ended (see Config.reward)
+// Returns a reward (0-1) and the sequence of moves played, including
+// automatic foundation moves
+func Simulate(gameState streets.StreetsGame, pathStates map[string]bool, cfg Config, rng *rand.Rand) (float64, []streets.Move) {
+	reward, moveHistory, _ := simulate(gameState, pathStates, cfg, rng)
+	return reward, moveHistory
+}
+
+// simulate is Simulate, also returning the keys of the moves it chose, not
+// counting automatic ones, when cfg uses RAVE
+func simulate(gameState streets.StreetsGame, pathStates map[string]bool, cfg Config, rng *rand.Rand) (float64, []streets.Move, []moveKey) {
+	autoplay := cfg.Autoplay
+	var keys []moveKey
+
+	// Make a copy of the game state to modify
+	currentState := gameState.Clone()
+	moveHistory := make([]streets.Move, 0)
+
+	// Make a local copy of seen states just for this simulation
+	seenStates := make(map[string]bool)
+	for state := range pathStates {
+		seenStates[state] = true
+	}
+
+	// Run simulation until we hit max moves or no legal moves remain
+	for moveCount := 0; moveCount < cfg.MaxRolloutLength; moveCount++ {
+		// Past the cutoff, judge the position rather than play it out
+		if moveCount == cfg.RolloutCutoff && cfg.RolloutCutoff > 0 {
+			return cfg.reward(&currentState, 0), moveHistory, keys
+		}
+
+		// Get legal moves
+		legalMoves := currentState.LegalMoves()
+
+		// Filter out moves that lead to previously seen states
+		validMoves := make([]streets.Move, 0)
+		for _, move := range legalMoves {
+			nextState := applyMove(currentState, move, autoplay)
+			if !seenStates[nextState.Hash()] {
+				validMoves = append(validMoves, move)
+			}
+		}
+
+		if len(validMoves) == 0 {
+			// All moves lead to previously seen states, evaluate position
+			return cfg.reward(&currentState, 0), moveHistory, keys
+		}
+
+		// Choose a move from valid moves as the rollout policy says
+		move := chooseRolloutMove(&currentState, validMoves, cfg, rng)
+		if cfg.RAVEEquivalence > 0 {
+			keys = append(keys, keyOf(&currentState, move))
+		}
+
+		// Apply move and any automatic moves that follow it
+		newState, _ := currentState.Apply(move)
+		newState, autoMoves := newState.ApplyAutoplay(autoplay)
+		seenStates[newState.Hash()] = true // Only track in local simulation
+
+		// Update current state
+		currentState = newState
+		moveHistory = append(moveHistory, move)
+		moveHistory = append(moveHistory, autoMoves...)
+	}
+	// Reached move limit, evaluate final position
+	return cfg.reward(&currentState, cfg.LimitBonus), moveHistory, keys //small bonus for reaching move limit
+}
+
+// reward scores the position a rollout ended in: by the fraction of cards
+// on the foundations, counting bonus extra cards, or with c.Evaluator if
+// c.EvalRollouts is set. A cutoff or leaf weight already brings the
+// evaluator's scores into the search, so with either of them every rollout
+// is scored by it too, keeping all rewards on one scale.
+func (c Config) reward(state *streets.StreetsGame, bonus float64) float64 {
+	if c.EvalRollouts || c.RolloutCutoff > 0 || c.LeafWeight > 0 {
+		return math.Pow(c.Evaluator.Evaluate(state), c.RewardPower)
+	}
+	cardsUp := float64(52-state.CountCardsInRows()) + bonus
+	return math.Pow(math.Min(cardsUp, 52)/52.0, c.RewardPower)
+}
+
+// BestMove returns the move with the highest visit count and its statistics
+func (n *Node) BestMove() (streets.Move, float64) {
+	bestVisits := -1
+	var bestMove streets.Move
+	bestReward := -1.0
+
+	for i, move := range n.Moves {
+		child := n.Children[move]
+		// If we find a perfect foundation move, return it immediately
+		reward := child.TotalReward / float64(child.Visits)
+		if reward == 1.0 && move.To == streets.Foundation {
+			return move, reward
+		}
+		if n.edgeVisits[i] > bestVisits {
+			bestVisits = n.edgeVisits[i]
+			bestMove = move
+			bestReward = reward
+		}
+	}
+	return bestMove, bestReward
+}
+
+// Search runs iterations from state on a new tree until cfg.Iterations have
+// run or cfg.MoveTime has passed, whichever comes first, and returns the
+// root. It also stops at deadline, if not zero, so a game's time budget can
+// cut the last move short; at least one iteration always runs. With
+// cfg.Threads above 1 the iterations are shared between that many
+// goroutines, and the result is no longer reproducible from rng. With
+// cfg.Ensemble above 1 the root returned pools the visits of that many
+// independent trees. With cfg.Transpositions the root's moves are in
+// canonical form, to be converted with state.CanonicalOrder().ToPhysical.
+func Search(state streets.StreetsGame, cfg Config, rng *rand.Rand, deadline time.Time) *Node {
+	rootNode, _ := search(nil, state, cfg, rng, deadline)
+	return rootNode
+}
+
+// search is Search, continuing from rootNode's statistics if it isn't nil,
+// and also reporting whether an ensemble's trees disagreed on the best move.
+// An ensemble always starts afresh.
+func search(rootNode *Node, state streets.StreetsGame, cfg Config, rng *rand.Rand, deadline time.Time) (*Node, bool) {
+	if cfg.Ensemble > 1 {
+		return searchEnsemble(state, cfg, rng, deadline)
+	}
+
+	if cfg.MoveTime > 0 {
+		if moveDeadline := time.Now().Add(cfg.MoveTime); deadline.IsZero() || moveDeadline.Before(deadline) {
+			deadline = moveDeadline
+		}
+	}
+
+	if rootNode == nil {
+		rootNode = NewNode(state.Hash())
+	}
+	if cfg.Threads > 1 {
+		searchParallel(state, rootNode, cfg, rng, deadline)
+		return rootNode, false
+	}
+	for i := 0; cfg.Iterations == 0 || i < cfg.Iterations; i++ {
+		if i > 0 && !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		Run(state, rootNode, cfg, rng)
+	}
+	return rootNode, false
+}
@@ -0,0 +1,288 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/exhaustive"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// midGame returns a position moves into a winning line of deal 8, found
+// with the exhaustive solver, where there are still plenty of choices and
+// move orders that transpose
+func midGame(t *testing.T, moves int) streets.StreetsGame {
+	t.Helper()
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	result := exhaustive.Solve(game, 200000, streets.AutoplayOff)
+	if result.Status != exhaustive.Winnable || len(result.Moves) < moves {
+		t.Fatalf("deal 8: %s with %d moves, expected a winning line", result.Status, len(result.Moves))
+	}
+	state, _, err := streets.Replay(game, result.Moves[:moves])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// checkEdges walks every path through the search graph from root, replaying
+// each node's moves against the position the path reached it in, and fails
+// if a move is illegal there or leads somewhere other than its child
+func checkEdges(t *testing.T, root *Node, state streets.StreetsGame, cfg Config) int {
+	t.Helper()
+	type visit struct {
+		node  *Node
+		state streets.StreetsGame
+	}
+
+	checked := 0
+	seen := make(map[*Node]map[streets.StreetsGame]bool)
+	queue := []visit{{root, state}}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if seen[v.node][v.state] {
+			continue
+		}
+		if seen[v.node] == nil {
+			seen[v.node] = make(map[streets.StreetsGame]bool)
+		}
+		seen[v.node][v.state] = true
+
+		for _, move := range v.node.Moves {
+			child := v.node.Children[move]
+			physical := physicalMove(v.state, move, cfg.Transpositions)
+			if !v.state.IsLegal(physical) {
+				t.Fatalf("%v (stored as %v) is illegal in\n%s", physical, move, v.state.ToString())
+			}
+			next := applyMove(v.state, physical, cfg.Autoplay)
+			if next.Hash() != child.GameStateHash {
+				t.Fatalf("%v from\n%s\ndoesn't lead to its child", physical, v.state.ToString())
+			}
+			checked++
+			queue = append(queue, visit{child, next})
+		}
+	}
+	return checked
+}
+
+func TestTranspositionEdgesAreLegal(t *testing.T) {
+	for _, moves := range []int{20, 40} {
+		state := midGame(t, moves)
+
+		cfg := DefaultConfig()
+		cfg.Iterations = 1000
+		cfg.Transpositions = true
+		root := Search(state, cfg, rand.New(rand.NewSource(1)), time.Time{})
+
+		if checked := checkEdges(t, root, state, cfg); checked == 0 {
+			t.Errorf("%d moves in: search made no edges", moves)
+		}
+	}
+}
+
+func TestReusedGraphMovesAreLegal(t *testing.T) {
+	game := midGame(t, 20)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 100
+	cfg.MaxMoves = 60
+	cfg.Transpositions = true
+	cfg.ReuseTree = true
+	result := PlayGame(game, cfg, rand.New(rand.NewSource(1)), nil)
+
+	if result.Stop == StopIllegal {
+		t.Fatalf("search chose an illegal move after %d moves", result.Searched)
+	}
+	if _, played, err := streets.Replay(game, result.Moves); err != nil {
+		t.Fatalf("game doesn't replay after %d moves: %v", played, err)
+	}
+}
+
+func TestEnsembleIterationsNotReused(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 50
+	cfg.MaxMoves = 10
+	cfg.Ensemble = 3
+	cfg.ReuseTree = true
+	result := PlayGame(game, cfg, rand.New(rand.NewSource(1)), nil)
+
+	if want := result.Searched * cfg.Ensemble * cfg.Iterations; result.Iterations != want {
+		t.Errorf("%d iterations over %d searched moves, want %d", result.Iterations, result.Searched, want)
+	}
+}
+
+func TestRolloutCutoffHandsOffToEvaluator(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Autoplay = streets.AutoplayOff
+	cfg.RolloutCutoff = 5
+	reward, moves := Simulate(game, nil, cfg, rand.New(rand.NewSource(1)))
+	if len(moves) != cfg.RolloutCutoff {
+		t.Fatalf("rollout played %d moves, want %d", len(moves), cfg.RolloutCutoff)
+	}
+
+	state, _, err := streets.Replay(game, moves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cfg.Evaluator.Evaluate(&state); reward != want {
+		t.Errorf("reward %v, want the evaluator's %v", reward, want)
+	}
+}
+
+func TestPlayGameIsSeeded(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(1234)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 30
+	cfg.MaxMoves = 20
+	first := PlayGame(game, cfg, rand.New(rand.NewSource(7)), nil)
+	again := PlayGame(game, cfg, rand.New(rand.NewSource(7)), nil)
+	if !reflect.DeepEqual(first.Moves, again.Moves) {
+		t.Errorf("seed 7 played\n%v\nthen\n%v", first.Moves, again.Moves)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   string // Start of the error, empty for a valid config
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"time instead of iterations", func(c *Config) { c.Iterations, c.MoveTime = 0, time.Second }, ""},
+		{"no budget", func(c *Config) { c.Iterations = 0 }, "need an iteration count"},
+		{"negative iterations", func(c *Config) { c.Iterations = -1 }, "iterations must not be negative"},
+		{"no rollout", func(c *Config) { c.MaxRolloutLength = 0 }, "rollout length"},
+		{"negative cutoff", func(c *Config) { c.RolloutCutoff = -1 }, "rollout cutoff"},
+		{"negative exploration", func(c *Config) { c.ExplorationConstant = -0.1 }, "exploration constant"},
+		{"zero reward power", func(c *Config) { c.RewardPower = 0 }, "reward power"},
+		{"negative limit bonus", func(c *Config) { c.LimitBonus = -1 }, "limit bonus"},
+		{"no moves", func(c *Config) { c.MaxMoves = 0 }, "max moves"},
+		{"no threads", func(c *Config) { c.Threads = 0 }, "threads"},
+		{"no ensemble", func(c *Config) { c.Ensemble = 0 }, "ensemble size"},
+		{"negative RAVE", func(c *Config) { c.RAVEEquivalence = -1 }, "RAVE equivalence"},
+		{"epsilon above 1", func(c *Config) { c.RolloutEpsilon = 1.5 }, "rollout epsilon"},
+		{"negative temperature", func(c *Config) { c.RolloutTemperature = -1 }, "rollout temperature"},
+		{"leaf weight above 1", func(c *Config) { c.LeafWeight = 2 }, "leaf weight"},
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		tt.change(&cfg)
+		err := cfg.Validate()
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want)):
+			t.Errorf("%s: error %v, want one starting %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSearchBudgets(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 25
+	if root := Search(game, cfg, rand.New(rand.NewSource(1)), time.Time{}); root.Visits != 25 {
+		t.Errorf("%d iterations, want 25", root.Visits)
+	}
+
+	// A time budget alone stops the search
+	cfg.Iterations = 0
+	cfg.MoveTime = 50 * time.Millisecond
+	start := time.Now()
+	root := Search(game, cfg, rand.New(rand.NewSource(1)), time.Time{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("50ms search took %v", elapsed)
+	}
+	if root.Visits == 0 {
+		t.Error("timed search ran no iterations")
+	}
+
+	// A deadline already passed still runs one iteration
+	cfg.MoveTime = 0
+	if root := Search(game, cfg, rand.New(rand.NewSource(1)), time.Now().Add(-time.Second)); root.Visits != 1 {
+		t.Errorf("%d iterations past the deadline, want 1", root.Visits)
+	}
+}
+
+func TestPlayGameBudgets(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 10
+	cfg.MaxMoves = 5
+	result := PlayGame(game, cfg, rand.New(rand.NewSource(1)), nil)
+	if result.Stop != StopMaxMoves || result.Searched != 5 {
+		t.Errorf("stopped with %s after %d searched moves, want the move limit after 5", result.Stop, result.Searched)
+	}
+
+	cfg.MaxMoves = 250
+	cfg.Iterations = 0
+	cfg.MoveTime = 20 * time.Millisecond
+	cfg.GameTime = 100 * time.Millisecond
+	result = PlayGame(game, cfg, rand.New(rand.NewSource(1)), nil)
+	if result.Stop != StopGameTime {
+		t.Errorf("stopped with %s after %d moves, want the game time", result.Stop, result.Searched)
+	}
+}
+
+func TestRewardScale(t *testing.T) {
+	state := midGame(t, 60)
+	cardsUp := float64(52-state.CountCardsInRows()+1) / 52
+	evaluated := streets.DefaultEvaluator().Evaluate(&state)
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   float64
+	}{
+		{"cards up by default", func(c *Config) {}, cardsUp},
+		{"evaluated rollouts", func(c *Config) { c.EvalRollouts = true }, evaluated},
+		{"with a cutoff", func(c *Config) { c.RolloutCutoff = 20 }, evaluated},
+		{"with a leaf weight", func(c *Config) { c.LeafWeight = 0.5 }, evaluated},
+		{"raised to the reward power", func(c *Config) { c.RolloutCutoff, c.RewardPower = 20, 2 }, evaluated * evaluated},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		tt.change(&cfg)
+		if got := cfg.reward(&state, 1); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: reward %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRolloutCutoffScoresEveryEnd(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	// Rollouts that end before the cutoff are still scored by the evaluator
+	cfg := DefaultConfig()
+	cfg.Autoplay = streets.AutoplayOff
+	cfg.RolloutCutoff = 100
+	cfg.MaxRolloutLength = 10
+	reward, moves := Simulate(game, nil, cfg, rand.New(rand.NewSource(1)))
+	state, _, err := streets.Replay(game, moves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cfg.Evaluator.Evaluate(&state); reward != want {
+		t.Errorf("reward %v after %d moves, want the evaluator's %v", reward, len(moves), want)
+	}
+}
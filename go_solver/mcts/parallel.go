@@ -0,0 +1,41 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// searchParallel runs Search's iterations from cfg.Threads goroutines on the
+// same tree. Each goroutine draws its rollouts from its own generator,
+// seeded from rng, and the tree is guarded by a single lock that is only
+// held to pick a path and to record a result; virtual losses on the nodes
+// an iteration is passing through keep the goroutines from all exploring
+// the same line.
+func searchParallel(state streets.StreetsGame, rootNode *Node, cfg Config, rng *rand.Rand, deadline time.Time) {
+	var tree sync.Mutex
+	var started atomic.Int64 // Iterations claimed so far, across all goroutines
+	var wg sync.WaitGroup
+
+	for t := 0; t < cfg.Threads; t++ {
+		threadRng := rand.New(rand.NewSource(rng.Int63()))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := started.Add(1) - 1
+				if cfg.Iterations > 0 && i >= int64(cfg.Iterations) {
+					return
+				}
+				if i > 0 && !deadline.IsZero() && time.Now().After(deadline) {
+					return
+				}
+				run(state, rootNode, cfg, threadRng, &tree)
+			}
+		}()
+	}
+	wg.Wait()
+}
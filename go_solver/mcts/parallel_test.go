@@ -0,0 +1,60 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestVirtualLossSteersSelection(t *testing.T) {
+	root := NewNode("root")
+	root.Visits = 10
+	better, worse := streets.Move{From: 0, To: 1}, streets.Move{From: 2, To: 3}
+	root.addChild(better, moveKey{}, &Node{GameStateHash: "better", Visits: 5, TotalReward: 3})
+	root.addChild(worse, moveKey{}, &Node{GameStateHash: "worse", Visits: 5, TotalReward: 2.5})
+	root.edgeVisits = []int{5, 5}
+
+	cfg := DefaultConfig()
+	if _, move, _ := root.selectChild(cfg, nil); move != better {
+		t.Fatalf("selected %v with nothing in flight, want %v", move, better)
+	}
+
+	// Iterations still running through the better child count as losses
+	root.Children[better].inFlight = 3
+	if _, move, _ := root.selectChild(cfg, nil); move != worse {
+		t.Errorf("selected %v with 3 iterations in flight through it, want %v", move, worse)
+	}
+}
+
+func TestParallelSearchSettles(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 200
+	cfg.Threads = 4
+	root := Search(game, cfg, rand.New(rand.NewSource(1)), time.Time{})
+	if root.Visits != cfg.Iterations {
+		t.Errorf("%d root visits, want %d", root.Visits, cfg.Iterations)
+	}
+
+	// Every virtual loss is taken back, and each child's visits are the
+	// visits made to it from its parent
+	nodes := []*Node{root}
+	for len(nodes) > 0 {
+		n := nodes[0]
+		nodes = nodes[1:]
+		if n.inFlight != 0 {
+			t.Fatalf("node has %d iterations still in flight", n.inFlight)
+		}
+		for i, move := range n.Moves {
+			child := n.Children[move]
+			if child.Visits != n.edgeVisits[i] {
+				t.Fatalf("child has %d visits but %d were made to it", child.Visits, n.edgeVisits[i])
+			}
+			nodes = append(nodes, child)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package mcts
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// Why PlayGame stopped
+const (
+	StopWon      = "won"       // Every card reached the foundations
+	StopNoMoves  = "no moves"  // No legal moves were left
+	StopMaxMoves = "max moves" // cfg.MaxMoves moves were played
+	StopGameTime = "game time" // cfg.GameTime ran out
+	StopIllegal  = "illegal"   // The search chose a move that isn't legal, which is a bug
+)
+
+// GameResult is the outcome of playing a whole game with PlayGame
+type GameResult struct {
+	Moves         []streets.Move      // Every move played, including automatic foundation moves
+	Final         streets.StreetsGame // Position at the end of the game
+	Searched      int                 // Moves chosen by search, not counting automatic ones
+	Iterations    int                 // MCTS iterations run across all of the searches
+	Disagreements int                 // Searched moves on which an ensemble's trees preferred different moves
+	Stop          string              // Why play stopped, one of the Stop constants
+}
+
+// Won reports whether every card reached the foundations
+func (r GameResult) Won() bool {
+	return r.Final.CountCardsInRows() == 0
+}
+
+// PlayGame plays game to the end with MCTS, searching before each move
+// within cfg's budgets, afresh or, with cfg.ReuseTree, from the subtree of
+// the move before. An ensemble always searches afresh. progress, if not nil,
+// is called after each searched move with its 0-based number and the
+// position it led to.
+func PlayGame(game streets.StreetsGame, cfg Config, rng *rand.Rand, progress func(moveNum int, state streets.StreetsGame)) GameResult {
+	var deadline time.Time
+	if cfg.GameTime > 0 {
+		deadline = time.Now().Add(cfg.GameTime)
+	}
+
+	// Start by recording any automatic moves the deal allows
+	currentState, moves := game.ApplyAutoplay(cfg.Autoplay)
+	result := GameResult{Stop: StopMaxMoves}
+	var reused *Node // Subtree kept from the previous search
+
+	for moveNum := 0; moveNum < cfg.MaxMoves; moveNum++ {
+		if currentState.CountCardsInRows() == 0 {
+			result.Stop = StopWon
+			break
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			result.Stop = StopGameTime
+			break
+		}
+
+		// Make the best move
+		carried := 0
+		if reused != nil {
+			carried = reused.Visits
+		}
+		rootNode, disagreed := search(reused, currentState, cfg, rng, deadline)
+		result.Iterations += rootNode.Visits - carried
+		if disagreed {
+			result.Disagreements++
+		}
+		bestMove, _ := rootNode.BestMove()
+		if bestMove == (streets.Move{}) {
+			result.Stop = StopNoMoves
+			break
+		}
+
+		// Apply the move and any automatic moves that follow it
+		move := bestMove
+		if cfg.Transpositions {
+			move = currentState.CanonicalOrder().ToPhysical(bestMove)
+		}
+		if !currentState.IsLegal(move) {
+			result.Stop = StopIllegal
+			break
+		}
+		nextState, _ := currentState.Apply(move)
+		nextState, autoMoves := nextState.ApplyAutoplay(cfg.Autoplay)
+		currentState = nextState
+
+		// Keep what was learned about the position the move led to
+		reused = nil
+		if cfg.ReuseTree && cfg.Ensemble == 1 {
+			reused = rootNode.promote(bestMove)
+		}
+
+		// Record the moves
+		moves = append(moves, move)
+		moves = append(moves, autoMoves...)
+		result.Searched++
+
+		if progress != nil {
+			progress(moveNum, currentState)
+		}
+	}
+
+	if result.Stop == StopMaxMoves && currentState.CountCardsInRows() == 0 {
+		result.Stop = StopWon
+	}
+	result.Moves = moves
+	result.Final = currentState
+	return result
+}
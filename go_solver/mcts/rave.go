@@ -0,0 +1,67 @@
+package mcts
+
+import "github.com/brettlyne/cards/go_solver/streets"
+
+// foundationTarget is the target rank of a moveKey for a move to the
+// foundations
+const foundationTarget = -1
+
+// moveKey identifies a move by what it does rather than where it is played:
+// the card moved and the rank of the card it lands on, 0 for an empty row or
+// foundationTarget for the foundations. "7H onto an 8" has the same key in
+// every position, whichever rows the cards are in.
+type moveKey struct {
+	card   streets.Card
+	target int
+}
+
+// keyOf returns the key of move in state
+func keyOf(state *streets.StreetsGame, move streets.Move) moveKey {
+	card, _ := state.LastCard(move.From)
+	if move.To == streets.Foundation {
+		return moveKey{card: card, target: foundationTarget}
+	}
+	target, _ := state.LastCard(move.To)
+	return moveKey{card: card, target: target.Value}
+}
+
+// amafStats total the rewards of iterations that played a move at or after
+// a node, wherever in the iteration it came
+type amafStats struct {
+	visits int
+	reward float64
+}
+
+// updateAMAF credits reward to every move the iteration played at or after
+// each node it passed through: the moves it took through the tree, then
+// rolloutKeys. A move played more than once counts once per node, as if it
+// had been played first.
+func updateAMAF(nodes []*Node, edges []int, rolloutKeys []moveKey, reward float64) {
+	played := make([]moveKey, 0, len(edges)+len(rolloutKeys))
+	for i, edge := range edges {
+		played = append(played, nodes[i].keys[edge])
+	}
+	played = append(played, rolloutKeys...)
+
+	seen := make(map[moveKey]bool)
+	for i, node := range nodes {
+		if i >= len(played) {
+			break
+		}
+		if node.amaf == nil {
+			node.amaf = make(map[moveKey]amafStats)
+		}
+
+		clear(seen)
+		for _, key := range played[i:] {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			stats := node.amaf[key]
+			stats.visits++
+			stats.reward += reward
+			node.amaf[key] = stats
+		}
+	}
+}
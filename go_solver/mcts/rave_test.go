@@ -0,0 +1,103 @@
+package mcts
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestKeyOf(t *testing.T) {
+	g := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	g.Rows[0][0] = streets.Card{Value: 8, Suit: "S"}
+	g.Rows[1][0] = streets.Card{Value: 8, Suit: "C"}
+	g.Rows[2][0] = streets.Card{Value: 7, Suit: "H"}
+	g.Rows[4][0] = streets.Card{Value: 1, Suit: "D"}
+
+	sevenOnEight := moveKey{card: streets.Card{Value: 7, Suit: "H"}, target: 8}
+	tests := []struct {
+		move streets.Move
+		want moveKey
+	}{
+		{streets.Move{From: 2, To: 0}, sevenOnEight},
+		{streets.Move{From: 2, To: 1}, sevenOnEight},
+		{streets.Move{From: 2, To: 3}, moveKey{card: streets.Card{Value: 7, Suit: "H"}, target: 0}},
+		{streets.Move{From: 4, To: streets.Foundation}, moveKey{card: streets.Card{Value: 1, Suit: "D"}, target: foundationTarget}},
+	}
+	for _, tt := range tests {
+		if got := keyOf(&g, tt.move); got != tt.want {
+			t.Errorf("%v: key %+v, want %+v", tt.move, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateAMAF(t *testing.T) {
+	key := func(value int) moveKey {
+		return moveKey{card: streets.Card{Value: value, Suit: "S"}, target: value + 1}
+	}
+
+	root, child := NewNode("root"), NewNode("child")
+	root.addChild(streets.Move{From: 0, To: 1}, key(1), child)
+	updateAMAF([]*Node{root, child}, []int{0}, []moveKey{key(2), key(3), key(2)}, 0.5)
+
+	// The root credits the tree move and every rollout move, the child only
+	// what came after it, and key(2) counts once however often it was played
+	want := map[moveKey]amafStats{key(1): {1, 0.5}, key(2): {1, 0.5}, key(3): {1, 0.5}}
+	if !reflect.DeepEqual(root.amaf, want) {
+		t.Errorf("root AMAF %v, want %v", root.amaf, want)
+	}
+	want = map[moveKey]amafStats{key(2): {1, 0.5}, key(3): {1, 0.5}}
+	if !reflect.DeepEqual(child.amaf, want) {
+		t.Errorf("child AMAF %v, want %v", child.amaf, want)
+	}
+}
+
+func TestRAVEGuidesUnvisitedMoves(t *testing.T) {
+	root := NewNode("root")
+	root.Visits = 5
+	visited, poor, promising := streets.Move{From: 0, To: 1}, streets.Move{From: 1, To: 2}, streets.Move{From: 2, To: 3}
+	root.addChild(visited, moveKey{target: 1}, &Node{GameStateHash: "visited", Visits: 5, TotalReward: 2.5})
+	root.addChild(poor, moveKey{target: 2}, NewNode("poor"))
+	root.addChild(promising, moveKey{target: 3}, NewNode("promising"))
+	root.edgeVisits = []int{5, 0, 0}
+	root.amaf = map[moveKey]amafStats{
+		{target: 2}: {visits: 4, reward: 0},
+		{target: 3}: {visits: 4, reward: 4},
+	}
+
+	// Without RAVE the first untried move is taken
+	cfg := DefaultConfig()
+	if _, move, _ := root.selectChild(cfg, nil); move != poor {
+		t.Errorf("selected %v without RAVE, want the first untried move %v", move, poor)
+	}
+
+	// With it, untried moves are ranked by how they did later in other lines
+	cfg.RAVEEquivalence = 300
+	if _, move, _ := root.selectChild(cfg, nil); move != promising {
+		t.Errorf("selected %v with RAVE, want %v", move, promising)
+	}
+}
+
+func TestSearchWithRAVE(t *testing.T) {
+	game := streets.StreetsGame{Variant: streets.StreetsAndAlleys}
+	game.Deal(8)
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 100
+	cfg.RAVEEquivalence = 300
+	root := Search(game, cfg, rand.New(rand.NewSource(1)), time.Time{})
+
+	// Every root move that was tried has AMAF statistics at least as large
+	// as its own, since playing it first counts too
+	for i, move := range root.Moves {
+		stats := root.amaf[root.keys[i]]
+		if stats.visits < root.edgeVisits[i] {
+			t.Errorf("%v: %d AMAF visits but %d of its own", move, stats.visits, root.edgeVisits[i])
+		}
+	}
+	if len(root.amaf) <= len(root.Moves) {
+		t.Errorf("root has AMAF statistics for %d moves, no more than its own %d", len(root.amaf), len(root.Moves))
+	}
+}
@@ -0,0 +1,156 @@
+package mcts
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// RolloutPolicy selects how simulations choose their moves
+type RolloutPolicy int
+
+const (
+	// RolloutRandom picks uniformly among the moves
+	RolloutRandom RolloutPolicy = iota
+	// RolloutFoundation prefers moves to the foundations
+	RolloutFoundation
+	// RolloutNoBury prefers moves that don't cover cards their foundations
+	// will soon need
+	RolloutNoBury
+	// RolloutEmptyRow prefers moves that empty a row
+	RolloutEmptyRow
+	// RolloutMixed adds up the scores of the three heuristics
+	RolloutMixed
+)
+
+// rolloutPolicies lists every policy, in the order their names are shown
+var rolloutPolicies = []RolloutPolicy{RolloutRandom, RolloutFoundation, RolloutNoBury, RolloutEmptyRow, RolloutMixed}
+
+// String returns the name used for the policy on the command line
+func (p RolloutPolicy) String() string {
+	switch p {
+	case RolloutFoundation:
+		return "foundation"
+	case RolloutNoBury:
+		return "no-bury"
+	case RolloutEmptyRow:
+		return "empty-row"
+	case RolloutMixed:
+		return "mixed"
+	default:
+		return "random"
+	}
+}
+
+// ParseRolloutPolicy parses a policy name such as "no-bury"
+func ParseRolloutPolicy(s string) (RolloutPolicy, error) {
+	for _, policy := range rolloutPolicies {
+		if s == policy.String() {
+			return policy, nil
+		}
+	}
+	return RolloutRandom, fmt.Errorf("unknown rollout policy: %s", s)
+}
+
+// score rates move in state between 0 and 1 for the policy, or up to 3 for
+// RolloutMixed; higher is better
+func (p RolloutPolicy) score(state *streets.StreetsGame, move streets.Move) float64 {
+	switch p {
+	case RolloutFoundation:
+		return foundationScore(move)
+	case RolloutNoBury:
+		return noBuryScore(state, move)
+	case RolloutEmptyRow:
+		return emptyRowScore(state, move)
+	case RolloutMixed:
+		return foundationScore(move) + noBuryScore(state, move) + emptyRowScore(state, move)
+	default:
+		return 0
+	}
+}
+
+// foundationScore is 1 for a move to the foundations
+func foundationScore(move streets.Move) float64 {
+	if move.To == streets.Foundation {
+		return 1
+	}
+	return 0
+}
+
+// noBuryScore is lower the sooner the cards the move covers will be needed
+// on their foundations: 0 for covering the next card of a suit, 1 for
+// covering nothing
+func noBuryScore(state *streets.StreetsGame, move streets.Move) float64 {
+	if move.To == streets.Foundation {
+		return 1
+	}
+
+	nearest := 13
+	for _, card := range state.Rows[move.To] {
+		if (card == streets.Card{}) {
+			break
+		}
+		nearest = min(nearest, state.FoundationDistance(card))
+	}
+	return float64(nearest) / 13
+}
+
+// emptyRowScore is 1 for a move that leaves its row empty, other than
+// moving a lone card to another empty row
+func emptyRowScore(state *streets.StreetsGame, move streets.Move) float64 {
+	if _, col := state.LastCard(move.From); col != 0 {
+		return 0
+	}
+	if move.To != streets.Foundation {
+		if _, col := state.LastCard(move.To); col == -1 {
+			return 0
+		}
+	}
+	return 1
+}
+
+// chooseRolloutMove picks one of moves for a simulation from state. A
+// heuristic policy plays its best scoring move (breaking ties at random),
+// except for a random move with probability cfg.RolloutEpsilon; with a
+// positive cfg.RolloutTemperature it instead draws moves with softmax
+// probabilities of their scores.
+func chooseRolloutMove(state *streets.StreetsGame, moves []streets.Move, cfg Config, rng *rand.Rand) streets.Move {
+	if cfg.Rollout == RolloutRandom {
+		return moves[rng.Intn(len(moves))]
+	}
+
+	scores := make([]float64, len(moves))
+	best := math.Inf(-1)
+	for i, move := range moves {
+		scores[i] = cfg.Rollout.score(state, move)
+		best = max(best, scores[i])
+	}
+
+	if cfg.RolloutTemperature > 0 {
+		total := 0.0
+		for i, score := range scores {
+			scores[i] = math.Exp((score - best) / cfg.RolloutTemperature)
+			total += scores[i]
+		}
+		pick := rng.Float64() * total
+		for i, weight := range scores {
+			if pick -= weight; pick < 0 {
+				return moves[i]
+			}
+		}
+		return moves[len(moves)-1]
+	}
+
+	if rng.Float64() < cfg.RolloutEpsilon {
+		return moves[rng.Intn(len(moves))]
+	}
+	var tied []streets.Move
+	for i, score := range scores {
+		if score == best {
+			tied = append(tied, moves[i])
+		}
+	}
+	return tied[rng.Intn(len(tied))]
+}
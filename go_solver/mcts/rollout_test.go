@@ -0,0 +1,104 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+// rolloutPosition has 2H ready for its foundation, 3H close behind it under
+// 9C, and 3S alone in a row
+func rolloutPosition() streets.StreetsGame {
+	g := streets.StreetsGame{Variant: streets.StreetsAndAlleys, Foundations: [4]int{1, 0, 0, 0}}
+	g.Rows[0][0] = streets.Card{Value: 5, Suit: "C"}
+	g.Rows[0][1] = streets.Card{Value: 2, Suit: "H"}
+	g.Rows[1][0] = streets.Card{Value: 3, Suit: "S"}
+	g.Rows[2][0] = streets.Card{Value: 4, Suit: "D"}
+	g.Rows[4][0] = streets.Card{Value: 3, Suit: "H"}
+	g.Rows[4][1] = streets.Card{Value: 9, Suit: "C"}
+	return g
+}
+
+func TestParseRolloutPolicy(t *testing.T) {
+	for _, policy := range rolloutPolicies {
+		if parsed, err := ParseRolloutPolicy(policy.String()); err != nil || parsed != policy {
+			t.Errorf("%s parsed as %s, %v", policy, parsed, err)
+		}
+	}
+	if _, err := ParseRolloutPolicy("greedy"); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
+
+func TestRolloutScores(t *testing.T) {
+	g := rolloutPosition()
+	toFoundation := streets.Move{From: 0, To: streets.Foundation}
+	onto3S := streets.Move{From: 0, To: 1}   // 2H covers 3S, two cards from its foundation
+	toEmpty := streets.Move{From: 0, To: 3}  // 2H covers nothing
+	emptying := streets.Move{From: 1, To: 2} // 3S leaves its row empty
+	loneToEmpty := streets.Move{From: 1, To: 3}
+
+	tests := []struct {
+		policy RolloutPolicy
+		move   streets.Move
+		want   float64
+	}{
+		{RolloutRandom, toFoundation, 0},
+		{RolloutFoundation, toFoundation, 1},
+		{RolloutFoundation, onto3S, 0},
+		{RolloutNoBury, toFoundation, 1},
+		{RolloutNoBury, onto3S, 2.0 / 13},
+		{RolloutNoBury, toEmpty, 1},
+		{RolloutEmptyRow, emptying, 1},
+		{RolloutEmptyRow, loneToEmpty, 0},
+		{RolloutEmptyRow, toFoundation, 0},
+		{RolloutMixed, toFoundation, 2},
+		{RolloutMixed, emptying, 1 + 3.0/13},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.score(&g, tt.move); got != tt.want {
+			t.Errorf("%s scores %v at %v, want %v", tt.policy, tt.move, got, tt.want)
+		}
+	}
+}
+
+func TestChooseRolloutMove(t *testing.T) {
+	g := rolloutPosition()
+	moves := g.LegalMoves()
+	toFoundation := streets.Move{From: 0, To: streets.Foundation}
+
+	// counts plays n rollout moves under cfg and counts each move chosen
+	counts := func(cfg Config, n int) map[streets.Move]int {
+		rng := rand.New(rand.NewSource(1))
+		chosen := make(map[streets.Move]int)
+		for i := 0; i < n; i++ {
+			chosen[chooseRolloutMove(&g, moves, cfg, rng)]++
+		}
+		return chosen
+	}
+
+	cfg := DefaultConfig()
+	cfg.Rollout = RolloutFoundation
+	cfg.RolloutEpsilon = 0
+	if chosen := counts(cfg, 200); chosen[toFoundation] != 200 {
+		t.Errorf("greedy foundation policy chose %v", chosen)
+	}
+
+	// Epsilon-greedy and softmax both sometimes play other moves
+	cfg.RolloutEpsilon = 0.5
+	if chosen := counts(cfg, 200); chosen[toFoundation] == 200 || chosen[toFoundation] < 100 {
+		t.Errorf("epsilon 0.5 chose the foundation move %d times in 200", chosen[toFoundation])
+	}
+	cfg.RolloutEpsilon = 0
+	cfg.RolloutTemperature = 1
+	if chosen := counts(cfg, 200); chosen[toFoundation] == 200 || chosen[toFoundation] < 20 {
+		t.Errorf("temperature 1 chose the foundation move %d times in 200", chosen[toFoundation])
+	}
+
+	// Random rollouts try every move
+	cfg.Rollout = RolloutRandom
+	if chosen := counts(cfg, 50*len(moves)); len(chosen) != len(moves) {
+		t.Errorf("random policy chose %d of %d moves", len(chosen), len(moves))
+	}
+}
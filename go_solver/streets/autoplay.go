@@ -0,0 +1,101 @@
+package streets
+
+import "fmt"
+
+// Autoplay selects which foundation moves are played automatically
+type Autoplay int
+
+const (
+	// AutoplayOff never plays moves automatically
+	AutoplayOff Autoplay = iota
+	// AutoplayAces plays aces (or whatever rank the foundations start from)
+	// to the foundation as soon as they are exposed
+	AutoplayAces
+	// AutoplaySafe plays any card that no remaining card could ever be built on.
+	// Where rows build down regardless of suit, a card is safe once every card
+	// one lower, of all four suits, is already on the foundation. Under other
+	// build rules it plays only the cards that start a foundation.
+	AutoplaySafe
+)
+
+// String returns the name used for the level on the command line
+func (a Autoplay) String() string {
+	switch a {
+	case AutoplayAces:
+		return "aces"
+	case AutoplaySafe:
+		return "safe"
+	default:
+		return "off"
+	}
+}
+
+// ParseAutoplay parses an autoplay level name ("off", "aces" or "safe")
+func ParseAutoplay(s string) (Autoplay, error) {
+	for _, level := range []Autoplay{AutoplayOff, AutoplayAces, AutoplaySafe} {
+		if s == level.String() {
+			return level, nil
+		}
+	}
+	return AutoplayOff, fmt.Errorf("unknown autoplay level: %s", s)
+}
+
+// isSafeFoundationMove reports whether moving card to the foundation is always
+// at least as good as keeping it in play
+func (g *StreetsGame) isSafeFoundationMove(card Card, level Autoplay) bool {
+	if !g.canPlayToFoundation(card) {
+		return false
+	}
+
+	// Choosing the base rank is up to the player
+	base := g.foundationBase()
+	if base == 0 {
+		return false
+	}
+	startsFoundation := card.Value == base && g.foundationCount(suitIndex(card.Suit)) == 0
+
+	switch {
+	case level == AutoplayAces:
+		return startsFoundation
+	case level == AutoplaySafe && g.Rules().Build() != streetsBuild:
+		return startsFoundation
+	case level == AutoplaySafe:
+		for _, top := range g.Foundations {
+			if top < card.Value-1 {
+				return false // A lower card may still need this one to build on
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplyAutoplay repeatedly plays safe foundation moves from g at the given
+// level until none remain. It returns the resulting position and the moves
+// played, in order, so they can be recorded alongside the move that led here.
+func (g StreetsGame) ApplyAutoplay(level Autoplay) (StreetsGame, []Move) {
+	state := g.Clone()
+	var moves []Move
+	if level == AutoplayOff {
+		return state, moves
+	}
+
+	for played := true; played; {
+		played = false
+
+		for row := 0; row < state.RowCount(); row++ {
+			card, col := state.LastCard(row)
+			if col == -1 || !state.isSafeFoundationMove(card, level) {
+				continue
+			}
+
+			move := Move{From: row, To: Foundation}
+			state, _ = state.Apply(move)
+			moves = append(moves, move)
+			played = true
+		}
+	}
+
+	return state, moves
+}
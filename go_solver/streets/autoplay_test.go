@@ -0,0 +1,61 @@
+package streets
+
+import "testing"
+
+func TestSafeFoundationMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+		found   [4]int // Foundation tops, in suit order H D C S
+		base    int
+		card    Card
+		level   Autoplay
+		want    bool
+	}{
+		{"ace is safe", StreetsAndAlleys, [4]int{}, 0, Card{1, "H"}, AutoplaySafe, true},
+		{"ace with aces autoplay", StreetsAndAlleys, [4]int{}, 0, Card{1, "S"}, AutoplayAces, true},
+		{"off plays nothing", StreetsAndAlleys, [4]int{}, 0, Card{1, "H"}, AutoplayOff, false},
+		{"two once every ace is up", StreetsAndAlleys, [4]int{1, 1, 1, 1}, 0, Card{2, "D"}, AutoplaySafe, true},
+		{"two while an ace is down", StreetsAndAlleys, [4]int{1, 1, 1, 0}, 0, Card{2, "D"}, AutoplaySafe, false},
+		{"two with aces autoplay", StreetsAndAlleys, [4]int{1, 1, 1, 1}, 0, Card{2, "D"}, AutoplayAces, false},
+		{"seven once every six is up", StreetsAndAlleys, [4]int{6, 6, 7, 8}, 0, Card{7, "H"}, AutoplaySafe, true},
+		{"seven while a five is down", StreetsAndAlleys, [4]int{6, 5, 7, 8}, 0, Card{7, "H"}, AutoplaySafe, false},
+		{"not next on its foundation", StreetsAndAlleys, [4]int{1, 1, 1, 1}, 0, Card{3, "H"}, AutoplaySafe, false},
+		{"beleaguered castle builds like streets", BeleagueredCastle, [4]int{2, 2, 2, 2}, 0, Card{3, "C"}, AutoplaySafe, true},
+		{"fortress only starts foundations", Fortress, [4]int{1, 1, 1, 1}, 0, Card{2, "H"}, AutoplaySafe, false},
+		{"fortress ace", Fortress, [4]int{}, 0, Card{1, "H"}, AutoplaySafe, true},
+		{"chessboard leaves the base to the player", Chessboard, [4]int{}, 0, Card{1, "H"}, AutoplaySafe, false},
+		{"chessboard starts at the chosen base", Chessboard, [4]int{0, 5, 0, 0}, 5, Card{5, "C"}, AutoplaySafe, true},
+		{"chessboard builds past the base", Chessboard, [4]int{5, 5, 5, 5}, 5, Card{6, "C"}, AutoplaySafe, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := StreetsGame{Variant: tt.variant, Foundations: tt.found, Base: tt.base}
+			if got := g.isSafeFoundationMove(tt.card, tt.level); got != tt.want {
+				t.Errorf("isSafeFoundationMove(%s, %s) = %v, want %v", tt.card, tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoplayMovesReplay(t *testing.T) {
+	for _, variant := range []Variant{StreetsAndAlleys, BeleagueredCastle, Citadel, Fortress} {
+		for dealNumber := int64(1); dealNumber <= 50; dealNumber++ {
+			g := StreetsGame{Variant: variant}
+			g.Deal(dealNumber)
+
+			state, moves := g.ApplyAutoplay(AutoplaySafe)
+			replayed, i, err := Replay(g, moves)
+			if err != nil {
+				t.Fatalf("%s deal %d: autoplay move %d: %v", variant, dealNumber, i, err)
+			}
+			if replayed != state {
+				t.Errorf("%s deal %d: replaying the autoplay moves gave a different position", variant, dealNumber)
+			}
+			if _, more := state.ApplyAutoplay(AutoplaySafe); len(more) != 0 {
+				t.Errorf("%s deal %d: autoplay stopped with %d safe moves left", variant, dealNumber, len(more))
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package streets
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type Card struct {
+	Value int    // 1 for Ace, 11 for Jack, 12 for Queen, 13 for King
+	Suit  string // "H" for Hearts, "D" for Diamonds, "C" for Clubs, "S" for Spades
+}
+
+// Convert card to string representation (e.g., "AS" for Ace of Spades)
+func (c Card) String() string {
+	if (c == Card{}) { // Empty card
+		return "  "
+	}
+
+	return RankString(c.Value) + c.Suit
+}
+
+// RankString returns the one character name of a card value ("A", "2"-"9",
+// "T", "J", "Q" or "K")
+func RankString(value int) string {
+	switch value {
+	case 1:
+		return "A"
+	case 10:
+		return "T"
+	case 11:
+		return "J"
+	case 12:
+		return "Q"
+	case 13:
+		return "K"
+	default:
+		return fmt.Sprintf("%d", value)
+	}
+}
+
+// parseRank parses the one character name of a card value
+func parseRank(r byte) (int, bool) {
+	switch r {
+	case 'A':
+		return 1, true
+	case 'T':
+		return 10, true
+	case 'J':
+		return 11, true
+	case 'Q':
+		return 12, true
+	case 'K':
+		return 13, true
+	default:
+		if r < '2' || r > '9' {
+			return 0, false
+		}
+		return int(r - '0'), true
+	}
+}
+
+// ParseCard parses a two character card such as "AS" or "TD"
+func ParseCard(s string) (Card, error) {
+	if len(s) != 2 {
+		return Card{}, fmt.Errorf("invalid card format: %s", s)
+	}
+
+	// Parse value
+	value, ok := parseRank(s[0])
+	if !ok {
+		return Card{}, fmt.Errorf("invalid card value: %s", s)
+	}
+
+	// Parse suit
+	suit := string(s[1])
+	if suit != "H" && suit != "D" && suit != "C" && suit != "S" {
+		return Card{}, fmt.Errorf("invalid suit: %s", s)
+	}
+
+	return Card{Value: value, Suit: suit}, nil
+}
+
+// suits lists the suits in foundation and hash order (see suitIndex)
+var suits = [4]string{"H", "D", "C", "S"}
+
+// Creates a new deck of 52 cards
+func createDeck() []Card {
+	deck := make([]Card, 52)
+	i := 0
+
+	for _, suit := range suits {
+		for value := 1; value <= 13; value++ {
+			deck[i] = Card{Value: value, Suit: suit}
+			i++
+		}
+	}
+
+	return deck
+}
+
+// Shuffles a deck of cards using Fisher-Yates algorithm
+func shuffleDeck(deck []Card, r *rand.Rand) {
+	for i := len(deck) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		deck[i], deck[j] = deck[j], deck[i]
+	}
+}
+
+// Compare two cards for sorting (lower value first, then H, D, C, S)
+func compareCards(a, b Card) bool {
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+
+	return suitIndex(a.Suit) < suitIndex(b.Suit)
+}
+
+// suitIndex maps a suit to its sort priority and hash value (H=0, D=1, C=2, S=3)
+func suitIndex(suit string) int {
+	switch suit {
+	case "H":
+		return 0
+	case "D":
+		return 1
+	case "C":
+		return 2
+	default:
+		return 3
+	}
+}
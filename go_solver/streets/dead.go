@@ -0,0 +1,137 @@
+package streets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dead reports whether g can be shown never to be won without searching it,
+// with a short explanation of why. A false result proves nothing.
+//
+// Besides positions with no moves at all, it finds deals where every row
+// holds a card that can't move until some row is empty: a card that must
+// wait for a lower card of its suit to go up first, where that card is
+// beneath it or itself stuck, and that has nowhere else to go because every
+// card it could be placed on is beneath it or buried under a stuck card. A
+// king over a lower card of its suit is the simplest case. Since the first
+// row to empty would have to lose such a card, no row ever empties, and the
+// stuck cards never reach the foundations.
+func (g *StreetsGame) Dead() (bool, string) {
+	if g.CountCardsInRows() == 0 {
+		return false, ""
+	}
+	if len(g.LegalMoves()) == 0 {
+		return true, "no card can move"
+	}
+
+	// Foundation order isn't known until the base rank is chosen
+	if g.foundationBase() == 0 {
+		return false, ""
+	}
+
+	rowCount := g.RowCount()
+	var lengths [MaxRows]int
+	for row := 0; row < rowCount; row++ {
+		for lengths[row] < 19 && (g.Rows[row][lengths[row]] != Card{}) {
+			lengths[row]++
+		}
+		if lengths[row] == 0 {
+			return false, "" // An empty row is already free
+		}
+	}
+
+	// Mark the cards that can't move before a row is empty, until no more
+	// can be found
+	var stuck [MaxRows][19]bool
+	var reasons [MaxRows][19]string
+	for changed := true; changed; {
+		changed = false
+		for row := 0; row < rowCount; row++ {
+			for col := lengths[row] - 1; col >= 0; col-- {
+				if stuck[row][col] {
+					continue
+				}
+				if col < lengths[row]-1 && stuck[row][col+1] {
+					stuck[row][col] = true // Covered by a stuck card
+					changed = true
+					continue
+				}
+
+				if reason := g.stuckReason(row, col, &lengths, &stuck); reason != "" {
+					stuck[row][col] = true
+					reasons[row][col] = reason
+					changed = true
+				}
+			}
+		}
+	}
+
+	// Any row without a stuck card might be emptied
+	explanations := make([]string, rowCount)
+	for row := 0; row < rowCount; row++ {
+		top := lengths[row] - 1
+		for top >= 0 && !stuck[row][top] {
+			top--
+		}
+		if top < 0 {
+			return false, ""
+		}
+		explanations[row] = fmt.Sprintf("row %d: %s", row+1, reasons[row][top])
+	}
+
+	return true, "no row can ever be emptied, since each holds a card that can't move until one is (" +
+		strings.Join(explanations, "; ") + ")"
+}
+
+// stuckReason explains why the card at row, col can't move before a row is
+// empty, given the cards already known to be stuck, or returns "" if it
+// might
+func (g *StreetsGame) stuckReason(row, col int, lengths *[MaxRows]int, stuck *[MaxRows][19]bool) string {
+	card := g.Rows[row][col]
+	distance := g.FoundationDistance(card)
+
+	// It can't go up while a card needed before it is beneath it or stuck
+	waiting := ""
+	for r := 0; r < g.RowCount() && waiting == ""; r++ {
+		for c := 0; c < lengths[r]; c++ {
+			other := g.Rows[r][c]
+			if other.Suit != card.Suit || g.FoundationDistance(other) >= distance {
+				continue
+			}
+			if r == row && c < col {
+				waiting = fmt.Sprintf("%s must wait for %s beneath it", card, other)
+				break
+			}
+			if stuck[r][c] {
+				waiting = fmt.Sprintf("%s must wait for %s, which is stuck", card, other)
+				break
+			}
+		}
+	}
+	if waiting == "" {
+		return ""
+	}
+
+	// Nor can it move onto a card that can't be uncovered
+	var targets []string
+	build := g.Rules().Build()
+	for r := 0; r < g.RowCount(); r++ {
+		covered := false // Whether a stuck card lies above c
+		for c := lengths[r] - 1; c >= 0; c-- {
+			covered = covered || (c < lengths[r]-1 && stuck[r][c+1])
+			target := g.Rows[r][c]
+			if !build.canBuildOn(card, target) {
+				continue
+			}
+			if !covered && !(r == row && c < col) {
+				return "" // It has somewhere to go
+			}
+			targets = append(targets, target.String())
+		}
+	}
+
+	if len(targets) == 0 {
+		return waiting + ", and no card can take it"
+	}
+	return waiting + ", and every card that could take it (" + strings.Join(targets, " ") + ") is buried"
+}
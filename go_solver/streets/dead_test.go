@@ -0,0 +1,57 @@
+package streets_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brettlyne/cards/go_solver/exhaustive"
+	"github.com/brettlyne/cards/go_solver/streets"
+)
+
+func TestDeadDealsAreNotSolved(t *testing.T) {
+	variants := []streets.Variant{streets.StreetsAndAlleys, streets.BeleagueredCastle, streets.Citadel, streets.Fortress}
+	for _, variant := range variants {
+		flagged := 0
+		for dealNumber := int64(1); dealNumber <= 1500; dealNumber++ {
+			game := streets.StreetsGame{Variant: variant}
+			game.Deal(dealNumber)
+			dead, reason := game.Dead()
+			if !dead {
+				continue
+			}
+			flagged++
+			if reason == "" {
+				t.Errorf("%s deal %d: dead with no reason", variant, dealNumber)
+			}
+
+			result := exhaustive.Solve(game, 100000, streets.AutoplayOff)
+			if result.Status == exhaustive.Winnable {
+				t.Errorf("%s deal %d is dead (%s) but was won in %d moves", variant, dealNumber, reason, len(result.Moves))
+			}
+		}
+		if flagged == 0 {
+			t.Errorf("%s: no deal found dead", variant)
+		}
+	}
+}
+
+func TestWinnableDealsAreNotDead(t *testing.T) {
+	content, err := os.ReadFile("../winnable_games_fixed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for gameNum, text := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		game, err := streets.Parse(text)
+		if err != nil {
+			t.Fatalf("game %d: %v", gameNum+1, err)
+		}
+		if dead, reason := game.Dead(); dead {
+			t.Errorf("game %d is winnable but found dead: %s", gameNum+1, reason)
+		}
+	}
+}
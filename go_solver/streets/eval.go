@@ -0,0 +1,173 @@
+package streets
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Indexes of the measurements in Features
+const (
+	FeatureCardsUp    = iota // Fraction of the cards on the foundations
+	FeatureNextBuried        // Cards covering the cards that could go up next, per 52
+	FeatureEmptyRows         // Fraction of the rows that are empty
+	FeatureRuns              // Runs of cards built on each other in the rows, per 52
+	FeatureBlocked           // Cards with a lower card of their suit beneath them in their row, per 52
+	NumFeatures
+)
+
+// FeatureNames names each feature, for reports and weight files
+var FeatureNames = [NumFeatures]string{"cards_up", "next_buried", "empty_rows", "runs", "blocked"}
+
+// Features are the measurements of a position the evaluator weighs
+type Features [NumFeatures]float64
+
+// Features measures g for the position evaluator
+func (g *StreetsGame) Features() Features {
+	build := g.Rules().Build()
+	rowCount := g.RowCount()
+	cardsInRows, emptyRows, runs, buried, blocked := 0, 0, 0, 0, 0
+
+	for row := 0; row < rowCount; row++ {
+		length := 0
+		for length < 19 && (g.Rows[row][length] != Card{}) {
+			length++
+		}
+		if length == 0 {
+			emptyRows++
+			continue
+		}
+		cardsInRows += length
+
+		for col := 0; col < length; col++ {
+			card := g.Rows[row][col]
+			distance := g.FoundationDistance(card)
+
+			// A run starts at every card that doesn't build on the one below
+			if col == 0 || !build.canBuildOn(card, g.Rows[row][col-1]) {
+				runs++
+			}
+			if distance == 0 {
+				buried += length - 1 - col
+			}
+			for below := 0; below < col; below++ {
+				under := g.Rows[row][below]
+				if under.Suit == card.Suit && g.FoundationDistance(under) < distance {
+					blocked++
+					break
+				}
+			}
+		}
+	}
+
+	var f Features
+	f[FeatureCardsUp] = float64(52-cardsInRows) / 52
+	f[FeatureNextBuried] = float64(buried) / 52
+	f[FeatureEmptyRows] = float64(emptyRows) / float64(rowCount)
+	f[FeatureRuns] = float64(runs) / 52
+	f[FeatureBlocked] = float64(blocked) / 52
+	return f
+}
+
+// Evaluator scores positions from their features, between 0 for hopeless
+// and 1 for won
+type Evaluator struct {
+	Weights  Features // Weight of each feature
+	Bias     float64  // Score before any feature is counted
+	Logistic bool     // Pass the weighted sum through the logistic function rather than clamping it
+}
+
+// DefaultEvaluator returns hand-set weights: cards up count most, and
+// buried next cards, disordered rows and blocked cards count against
+func DefaultEvaluator() Evaluator {
+	return Evaluator{
+		Weights: Features{
+			FeatureCardsUp:    0.5,
+			FeatureNextBuried: -0.25,
+			FeatureEmptyRows:  0.1,
+			FeatureRuns:       -0.2,
+			FeatureBlocked:    -0.2,
+		},
+		Bias: 0.5,
+	}
+}
+
+// Evaluate scores g between 0 and 1; a won position always scores 1
+func (e Evaluator) Evaluate(g *StreetsGame) float64 {
+	if g.CountCardsInRows() == 0 {
+		return 1
+	}
+	return e.Score(g.Features())
+}
+
+// Score scores a position from its features, between 0 and 1
+func (e Evaluator) Score(f Features) float64 {
+	sum := e.Bias
+	for i, weight := range e.Weights {
+		sum += weight * f[i]
+	}
+	if e.Logistic {
+		return 1 / (1 + math.Exp(-sum))
+	}
+	return math.Max(0, math.Min(1, sum))
+}
+
+// evaluatorJSON is how an Evaluator is saved, with the weights by feature
+// name so that files stay readable and survive features being added
+type evaluatorJSON struct {
+	Model   string             `json:"model"` // "linear" or "logistic"
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+// MarshalJSON writes e with its weights keyed by feature name
+func (e Evaluator) MarshalJSON() ([]byte, error) {
+	saved := evaluatorJSON{Model: "linear", Bias: e.Bias, Weights: make(map[string]float64)}
+	if e.Logistic {
+		saved.Model = "logistic"
+	}
+	for i, name := range FeatureNames {
+		saved.Weights[name] = e.Weights[i]
+	}
+	return json.Marshal(saved)
+}
+
+// UnmarshalJSON reads an evaluator written by MarshalJSON; features it
+// doesn't mention get no weight
+func (e *Evaluator) UnmarshalJSON(data []byte) error {
+	var saved evaluatorJSON
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	loaded := Evaluator{Bias: saved.Bias}
+	switch saved.Model {
+	case "linear":
+	case "logistic":
+		loaded.Logistic = true
+	default:
+		return fmt.Errorf("unknown evaluator model: %q", saved.Model)
+	}
+
+	for name, weight := range saved.Weights {
+		found := false
+		for i, featureName := range FeatureNames {
+			if name == featureName {
+				loaded.Weights[i] = weight
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown feature: %q", name)
+		}
+	}
+
+	*e = loaded
+	return nil
+}
+
+// Health scores g with the default evaluator, for showing players how
+// their position looks
+func (g *StreetsGame) Health() float64 {
+	return DefaultEvaluator().Evaluate(g)
+}
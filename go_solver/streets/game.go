@@ -0,0 +1,451 @@
+// Package streets implements the Streets and Alleys solitaire engine:
+// game state, parsing, hashing and move generation.
+package streets
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// MaxRows is the most rows any variant plays with; rows past the variant's
+// RowCount stay empty
+const MaxRows = 10
+
+// streets and alleys game state representation
+// Foundations holds the value of the top card on each suit's foundation
+// (0 when empty), indexed in suits order: H, D, C, S
+// Base is the rank the foundations start from in variants where the first
+// foundation card chooses it (0 until then, and in every other variant)
+// Variant selects the rules the game is dealt and played by
+type StreetsGame struct {
+	Rows        [MaxRows][19]Card
+	Foundations [4]int
+	Base        int
+	Variant     Variant
+}
+
+// Parse returns the game state described by s (see FromString)
+func Parse(s string) (StreetsGame, error) {
+	var g StreetsGame
+	err := g.FromString(s)
+	return g, err
+}
+
+// Reset deals a randomly chosen deal and returns its deal number, which can
+// be passed to Deal to get the same layout again
+func (g *StreetsGame) Reset() int64 {
+	dealNumber := rand.Int63n(math.MaxInt32) + 1
+	g.Deal(dealNumber)
+	return dealNumber
+}
+
+// Deal deals the deck shuffled by the given deal number into the layout for
+// the game's variant
+// The same deal number always gives the same shuffle, whatever the variant
+func (g *StreetsGame) Deal(dealNumber int64) {
+	// Clear current game state
+	g.Rows = [MaxRows][19]Card{}
+	g.Foundations = [4]int{}
+	g.Base = 0
+
+	// Create and shuffle deck
+	deck := createDeck()
+	shuffleDeck(deck, rand.New(rand.NewSource(dealNumber)))
+
+	// Deal cards
+	g.Rules().Deal(g, deck)
+}
+
+// ToString converts the game state to a string representation
+// A "variant:" line for variants other than Streets and Alleys, then one line
+// per row, with EmptyRow for a row without cards, followed by a "base:" line
+// once a chosen base rank is set and a "foundations:" line listing the top
+// card of each non-empty foundation if any cards have been played there
+func (g *StreetsGame) ToString() string {
+	var result strings.Builder
+
+	if g.Variant != StreetsAndAlleys {
+		result.WriteString("variant: " + g.Variant.String() + "\n")
+	}
+
+	for row := 0; row < g.RowCount(); row++ {
+		if row > 0 {
+			result.WriteString("\n")
+		}
+
+		if g.getRowLength(row) == 0 {
+			result.WriteString(EmptyRow)
+			continue
+		}
+
+		firstCard := true
+		for col := 0; col < 19; col++ {
+			card := g.Rows[row][col]
+			if (card != Card{}) {
+				if !firstCard {
+					result.WriteString(" ")
+				}
+				result.WriteString(card.String())
+				firstCard = false
+			}
+		}
+	}
+
+	if g.Base != 0 {
+		result.WriteString("\nbase: " + RankString(g.Base))
+	}
+	if g.Foundations != [4]int{} {
+		result.WriteString("\nfoundations: " + formatFoundations(g.Foundations))
+	}
+
+	return result.String()
+}
+
+// formatFoundations lists the top card of each non-empty foundation
+func formatFoundations(foundations [4]int) string {
+	var tops []string
+	for suit, top := range foundations {
+		if top > 0 {
+			tops = append(tops, Card{Value: top, Suit: suits[suit]}.String())
+		}
+	}
+	return strings.Join(tops, " ")
+}
+
+// inferFoundations puts every card that is missing from the rows on the
+// foundations, counting up from the base rank of each suit to the first card
+// still in a row. It can't tell where the foundations start when the base is
+// still to be chosen, so it leaves them empty.
+func (g *StreetsGame) inferFoundations() {
+	g.Foundations = [4]int{}
+	base := g.foundationBase()
+	if base == 0 {
+		return
+	}
+
+	var inRows [4][14]bool
+	for row := 0; row < MaxRows; row++ {
+		for col := 0; col < 19; col++ {
+			if card := g.Rows[row][col]; (card != Card{}) {
+				inRows[suitIndex(card.Suit)][card.Value] = true
+			}
+		}
+	}
+
+	for suit := range g.Foundations {
+		value := base
+		for count := 0; count < 13 && !inRows[suit][value]; count++ {
+			g.Foundations[suit] = value
+			value = value%13 + 1
+		}
+	}
+}
+
+// Print displays the current game state
+func (g *StreetsGame) Print() {
+	fmt.Println(g.ToString())
+}
+
+// Get row length (number of non-empty cards)
+func (g *StreetsGame) getRowLength(row int) int {
+	length := 0
+	for col := 0; col < 19; col++ {
+		if (g.Rows[row][col] != Card{}) {
+			length++
+		}
+	}
+	return length
+}
+
+// Get first card in row, or empty card if row is empty
+func (g *StreetsGame) getFirstCard(row int) Card {
+	for col := 0; col < 19; col++ {
+		if (g.Rows[row][col] != Card{}) {
+			return g.Rows[row][col]
+		}
+	}
+	return Card{}
+}
+
+// RowOrder maps canonical row positions to physical rows: RowOrder[i] is the
+// physical row that sorts into position i
+type RowOrder [MaxRows]int
+
+// ToPhysical converts a move between canonical row positions into the same
+// move between physical rows
+func (o RowOrder) ToPhysical(m Move) Move {
+	physical := Move{From: o[m.From], To: m.To}
+	if m.To != Foundation {
+		physical.To = o[m.To]
+	}
+	return physical
+}
+
+// ToCanonical converts a move between physical rows into the same move
+// between canonical row positions
+func (o RowOrder) ToCanonical(m Move) Move {
+	var position [MaxRows]int
+	for i, row := range o {
+		position[row] = i
+	}
+
+	canonical := Move{From: position[m.From], To: m.To}
+	if m.To != Foundation {
+		canonical.To = position[m.To]
+	}
+	return canonical
+}
+
+// CanonicalOrder returns the order rows are hashed in without modifying the
+// game: longest first, break ties with first card (lowest value, then H,D,C,S)
+func (g StreetsGame) CanonicalOrder() RowOrder {
+	var order RowOrder
+	var lengths [MaxRows]int
+	var firstCards [MaxRows]Card
+	for row := 0; row < MaxRows; row++ {
+		order[row] = row
+		lengths[row] = g.getRowLength(row)
+		firstCards[row] = g.getFirstCard(row)
+	}
+
+	sort.SliceStable(order[:], func(i, j int) bool {
+		rowI, rowJ := order[i], order[j]
+
+		// If lengths are different, longer row comes first
+		if lengths[rowI] != lengths[rowJ] {
+			return lengths[rowI] > lengths[rowJ]
+		}
+
+		// Empty rows are all alike
+		if lengths[rowI] == 0 {
+			return false
+		}
+
+		return compareCards(firstCards[rowI], firstCards[rowJ])
+	})
+
+	return order
+}
+
+// NormalizeRows reorders the rows into canonical order (see CanonicalOrder)
+func (g *StreetsGame) NormalizeRows() {
+	order := g.CanonicalOrder()
+
+	// Create new array with sorted rows
+	newRows := [MaxRows][19]Card{}
+	for newPos, oldPos := range order {
+		newRows[newPos] = g.Rows[oldPos]
+	}
+
+	// Update game state
+	g.Rows = newRows
+}
+
+// Hash generates a compact string representation of the game state with its
+// rows in canonical order, so positions that differ only by row order hash
+// the same. The game itself is left untouched.
+// The four foundation values come first as 6-bit numbers (0-13, in H, D, C, S order),
+// followed by the chosen base rank (0 when not chosen) and the variant, so
+// the same layout under different rules hashes differently
+// Each card is then encoded as a 6-bit number (0-51) where:
+// - Value is (card.Value - 1) * 4 (0-48)
+// - Suit adds 0-3 (Hearts=0, Diamonds=1, Clubs=2, Spades=3)
+// Rows are separated by the delimiter value 63 (111111 in binary)
+// The last byte is padded with 1 bits
+func (g StreetsGame) Hash() string {
+	// Each card and delimiter takes 6 bits
+	result := make([]byte, 0, 40) // Max capacity needed
+
+	var accumulator uint32
+	var bitsInAccumulator uint8
+
+	// Helper to add 6 bits to our bit stream
+	add6Bits := func(value byte) {
+		accumulator = (accumulator << 6) | uint32(value)
+		bitsInAccumulator += 6
+
+		// While we have 8 or more bits, extract bytes
+		for bitsInAccumulator >= 8 {
+			result = append(result, byte(accumulator>>(bitsInAccumulator-8)))
+			bitsInAccumulator -= 8
+			accumulator &= (1 << bitsInAccumulator) - 1
+		}
+	}
+
+	// Foundations first
+	for _, top := range g.Foundations {
+		add6Bits(byte(top))
+	}
+	add6Bits(byte(g.Base))
+	add6Bits(byte(g.Variant))
+
+	// Process all cards
+	for position, row := range g.CanonicalOrder() {
+		// Add cards in this row
+		for col := 0; col < 19; col++ {
+			card := g.Rows[row][col]
+			if (card != Card{}) {
+				cardValue := byte((card.Value-1)*4 + suitIndex(card.Suit))
+				add6Bits(cardValue)
+			}
+		}
+
+		// Add row delimiter (63 = 111111 in binary)
+		if position < MaxRows-1 { // Don't need delimiter after last row
+			add6Bits(63)
+		}
+	}
+
+	// Add any remaining bits with padding
+	if bitsInAccumulator > 0 {
+		padding := 8 - bitsInAccumulator
+		accumulator = (accumulator << padding) | (1<<padding - 1)
+		result = append(result, byte(accumulator))
+	}
+
+	return string(result)
+}
+
+// FromHash reconstructs a game state, variant included, from its hash
+// representation. The rows come back in canonical order.
+func (g *StreetsGame) FromHash(hash string) error {
+	// Clear current state
+	g.Rows = [MaxRows][19]Card{}
+	g.Foundations = [4]int{}
+	g.Base = 0
+	g.Variant = StreetsAndAlleys
+
+	// Convert string back to bytes
+	data := []byte(hash)
+	if len(data) == 0 {
+		return fmt.Errorf("empty hash")
+	}
+
+	// Every whole 6-bit group is a value; bits left over are padding
+	groups := len(data) * 8 / 6
+
+	// Track current position
+	var accumulator uint32
+	var bitsInAccumulator uint8
+	currentRow := 0
+	currentCol := 0
+
+	// Helper to get next 6 bits
+	getNext6Bits := func() byte {
+		// Fill accumulator if needed
+		for bitsInAccumulator < 6 && len(data) > 0 {
+			accumulator = (accumulator << 8) | uint32(data[0])
+			bitsInAccumulator += 8
+			data = data[1:]
+		}
+
+		// Extract top 6 bits
+		result := byte(accumulator>>(bitsInAccumulator-6)) & 0x3F
+		bitsInAccumulator -= 6
+		accumulator &= (1 << bitsInAccumulator) - 1
+
+		return result
+	}
+
+	// Foundations first
+	if groups < len(g.Foundations)+2 {
+		return fmt.Errorf("incomplete foundation data")
+	}
+	for suit := range g.Foundations {
+		top := getNext6Bits()
+		if top > 13 {
+			return fmt.Errorf("invalid foundation value for %s: %d", suits[suit], top)
+		}
+		g.Foundations[suit] = int(top)
+	}
+	base := getNext6Bits()
+	if base > 13 {
+		return fmt.Errorf("invalid base rank: %d", base)
+	}
+	g.Base = int(base)
+	variant := getNext6Bits()
+	if int(variant) >= len(variants) {
+		return fmt.Errorf("invalid variant: %d", variant)
+	}
+	g.Variant = Variant(variant)
+
+	// Process all cards
+	for i := len(g.Foundations) + 2; i < groups; i++ {
+		cardValue := getNext6Bits()
+
+		if cardValue == 63 {
+			if currentRow == MaxRows-1 {
+				break // Padding after the last row
+			}
+
+			// Row delimiter
+			currentRow++
+			currentCol = 0
+			continue
+		}
+		if cardValue >= 52 {
+			return fmt.Errorf("invalid card value: %d", cardValue)
+		}
+
+		// Convert 6-bit value back to card
+		cardNum := int(cardValue/4) + 1
+		suit := suits[cardValue%4]
+
+		if currentCol >= 19 {
+			return fmt.Errorf("too many cards in row %d", currentRow)
+		}
+
+		g.Rows[currentRow][currentCol] = Card{Value: cardNum, Suit: suit}
+		currentCol++
+	}
+
+	return nil
+}
+
+// Equals compares this game state with another game state, ignoring row order
+func (g StreetsGame) Equals(other StreetsGame) bool {
+	if g.Variant != other.Variant || g.Foundations != other.Foundations || g.Base != other.Base {
+		return false
+	}
+
+	// First normalize both states
+	g.NormalizeRows()
+	other.NormalizeRows()
+
+	// Compare each position
+	for row := 0; row < MaxRows; row++ {
+		for col := 0; col < 19; col++ {
+			if g.Rows[row][col] != other.Rows[row][col] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of the game state
+func (g StreetsGame) Clone() StreetsGame {
+	var clone StreetsGame
+	clone.Rows = g.Rows // This works because arrays are copied by value in Go
+	clone.Foundations = g.Foundations
+	clone.Base = g.Base
+	clone.Variant = g.Variant
+	return clone
+}
+
+// CountCardsInRows returns the total number of cards still in the rows (not in foundations)
+func (g *StreetsGame) CountCardsInRows() int {
+	count := 0
+	for row := 0; row < MaxRows; row++ {
+		for col := 0; col < 19; col++ {
+			if (g.Rows[row][col] != Card{}) {
+				count++
+			}
+		}
+	}
+	return count
+}
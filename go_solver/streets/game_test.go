@@ -0,0 +1,93 @@
+package streets
+
+import "testing"
+
+func TestHashRoundTrip(t *testing.T) {
+	for _, variant := range variants {
+		for dealNumber := int64(1); dealNumber <= 20; dealNumber++ {
+			g := StreetsGame{Variant: variant}
+			g.Deal(dealNumber)
+
+			// Check the deal and a few positions along a game
+			for step := 0; step < 30; step++ {
+				var decoded StreetsGame
+				if err := decoded.FromHash(g.Hash()); err != nil {
+					t.Fatalf("%s deal %d step %d: FromHash: %v", variant, dealNumber, step, err)
+				}
+				if !decoded.Equals(g) {
+					t.Fatalf("%s deal %d step %d: round trip gave\n%s\nwant\n%s",
+						variant, dealNumber, step, decoded.ToString(), g.ToString())
+				}
+
+				moves := g.LegalMoves()
+				if len(moves) == 0 {
+					break
+				}
+				next, err := g.Apply(moves[(step*7+int(dealNumber))%len(moves)])
+				if err != nil {
+					t.Fatal(err)
+				}
+				g = next
+			}
+		}
+	}
+}
+
+func TestHashDoesNotMutate(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(42)
+
+	// Put the rows out of canonical order
+	g.Rows[0], g.Rows[7] = g.Rows[7], g.Rows[0]
+	g.Rows[4][5] = Card{}
+	before := g
+
+	g.Hash()
+	g.CanonicalOrder()
+	if g != before {
+		t.Errorf("Hash modified the game:\n%s\nwant\n%s", g.ToString(), before.ToString())
+	}
+}
+
+func TestHashIgnoresRowOrder(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(7)
+
+	swapped := g
+	swapped.Rows[1], swapped.Rows[6] = swapped.Rows[6], swapped.Rows[1]
+	if g.Hash() != swapped.Hash() {
+		t.Error("swapping two rows changed the hash")
+	}
+}
+
+func TestRowOrderMapsMoves(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(3)
+	g.Rows[2], g.Rows[5] = g.Rows[5], g.Rows[2]
+
+	order := g.CanonicalOrder()
+	for _, move := range g.LegalMoves() {
+		if got := order.ToPhysical(order.ToCanonical(move)); got != move {
+			t.Errorf("%v mapped to canonical and back is %v", move, got)
+		}
+	}
+}
+
+func TestHashIncludesVariant(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(5)
+
+	castle := g
+	castle.Variant = BeleagueredCastle
+	if g.Hash() == castle.Hash() {
+		t.Error("the same layout hashes the same under two variants")
+	}
+
+	var decoded StreetsGame
+	if err := decoded.FromHash(castle.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Variant != BeleagueredCastle {
+		t.Errorf("FromHash gave variant %s, want %s", decoded.Variant, BeleagueredCastle)
+	}
+}
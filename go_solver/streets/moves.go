@@ -0,0 +1,175 @@
+package streets
+
+import "fmt"
+
+const (
+	// Foundation represents moving a card to the foundation
+	Foundation = -1
+)
+
+// Move represents moving a card from one row to another
+// If To is Foundation, the card is moved to the foundation
+type Move struct {
+	From int
+	To   int
+}
+
+// String returns a human-readable representation of the move
+func (m Move) String() string {
+	if m.To == Foundation {
+		return fmt.Sprintf("from row %d to foundation", m.From)
+	}
+	return fmt.Sprintf("from row %d to row %d", m.From, m.To)
+}
+
+// LastCard returns the last card in a row and its column position
+// If the row is empty, returns an empty card and -1
+func (g *StreetsGame) LastCard(row int) (Card, int) {
+	for col := 18; col >= 0; col-- {
+		if (g.Rows[row][col] != Card{}) {
+			return g.Rows[row][col], col
+		}
+	}
+	return Card{}, -1
+}
+
+// FoundationTops returns the value of the top card on each suit's
+// foundation (0 when empty), keyed by suit
+func (g *StreetsGame) FoundationTops() map[string]int {
+	tops := make(map[string]int)
+	for suit, top := range g.Foundations {
+		tops[suits[suit]] = top
+	}
+	return tops
+}
+
+// foundationBase returns the rank every foundation starts from, or 0 if the
+// variant lets the first foundation card choose it and none has been played
+func (g *StreetsGame) foundationBase() int {
+	if base := g.Rules().Build().Base; base != 0 {
+		return base
+	}
+	return g.Base
+}
+
+// foundationCount returns how many cards are on a suit's foundation
+func (g *StreetsGame) foundationCount(suit int) int {
+	top := g.Foundations[suit]
+	if top == 0 {
+		return 0
+	}
+	return (top-g.foundationBase()+13)%13 + 1
+}
+
+// onFoundation reports whether card has already been played to its foundation
+func (g *StreetsGame) onFoundation(card Card) bool {
+	count := g.foundationCount(suitIndex(card.Suit))
+	return count > 0 && (card.Value-g.foundationBase()+13)%13 < count
+}
+
+// FoundationDistance returns how many cards must reach card's foundation
+// before card can, 0 if it could be played there now. Until a variant that
+// lets the first foundation card choose the base has had one played, every
+// card counts as playable.
+func (g *StreetsGame) FoundationDistance(card Card) int {
+	base := g.foundationBase()
+	if base == 0 {
+		return 0
+	}
+	return (card.Value-base+13)%13 - g.foundationCount(suitIndex(card.Suit))
+}
+
+// canPlayToFoundation reports whether card is the next card for its foundation
+func (g *StreetsGame) canPlayToFoundation(card Card) bool {
+	base := g.foundationBase()
+	if base == 0 {
+		return true // This card will choose the base
+	}
+
+	suit := suitIndex(card.Suit)
+	switch count := g.foundationCount(suit); count {
+	case 0:
+		return card.Value == base
+	case 13:
+		return false
+	default:
+		return card.Value == g.Foundations[suit]%13+1
+	}
+}
+
+// playToFoundation puts card on its foundation, choosing the base rank if
+// it is the first foundation card in a variant that lets it
+func (g *StreetsGame) playToFoundation(card Card) {
+	if g.foundationBase() == 0 {
+		g.Base = card.Value
+	}
+	g.Foundations[suitIndex(card.Suit)] = card.Value
+}
+
+// LegalMoves returns all legal moves in the current game state under its
+// variant's rules
+func (g *StreetsGame) LegalMoves() []Move {
+	return g.Rules().LegalMoves(g)
+}
+
+// Apply returns a new game state that results from applying the move
+func (g *StreetsGame) Apply(move Move) (StreetsGame, error) {
+	return g.Rules().Apply(g, move)
+}
+
+// IsLegal reports whether the move is one LegalMoves would generate
+func (g *StreetsGame) IsLegal(move Move) bool {
+	for _, legal := range g.LegalMoves() {
+		if legal == move {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay plays moves in order from g, stopping at the first move that is not
+// legal. It returns the position reached and how many moves were applied.
+func Replay(g StreetsGame, moves []Move) (StreetsGame, int, error) {
+	state := g.Clone()
+
+	for i, move := range moves {
+		if !state.IsLegal(move) {
+			return state, i, fmt.Errorf("illegal move %d: %s", i, move)
+		}
+
+		nextState, err := state.Apply(move)
+		if err != nil {
+			return state, i, fmt.Errorf("move %d: %w", i, err)
+		}
+		state = nextState
+	}
+
+	return state, len(moves), nil
+}
+
+// PhysicalMoves converts a move list recorded against normalized positions,
+// where each move indexes the rows as NormalizeRows would order them just
+// before it is played, into the same moves on the physical rows of g.
+// Older solver logs were written this way because Hash used to normalize
+// the game it was called on.
+func PhysicalMoves(g StreetsGame, canonical []Move) ([]Move, error) {
+	physical := make([]Move, 0, len(canonical))
+	state := g.Clone()
+
+	for i, move := range canonical {
+		if move.From < 0 || move.From >= g.RowCount() || move.To < Foundation || move.To >= g.RowCount() {
+			return physical, fmt.Errorf("move %d: row out of range: %s", i, move)
+		}
+		move = state.CanonicalOrder().ToPhysical(move)
+
+		nextState, err := state.Apply(move)
+		if err != nil {
+			return physical, fmt.Errorf("move %d: %w", i, err)
+		}
+
+		physical = append(physical, move)
+		state = nextState
+	}
+
+	return physical, nil
+}
@@ -0,0 +1,26 @@
+package streets
+
+import "testing"
+
+func TestApplyRejectsMissingRows(t *testing.T) {
+	for _, variant := range []Variant{StreetsAndAlleys, BeleagueredCastle, Citadel, Fortress, Chessboard} {
+		g := StreetsGame{Variant: variant}
+		g.Deal(1)
+
+		for _, move := range []Move{
+			{From: -1, To: 0},
+			{From: -2, To: Foundation},
+			{From: g.RowCount(), To: 0},
+			{From: MaxRows, To: Foundation},
+			{From: 0, To: g.RowCount()},
+			{From: 0, To: -2},
+		} {
+			if _, err := g.Apply(move); err == nil {
+				t.Errorf("%s: %v was applied", variant, move)
+			}
+			if g.IsLegal(move) {
+				t.Errorf("%s: %v is legal", variant, move)
+			}
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package streets
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseMode selects how strictly FromStringMode checks a position
+type ParseMode int
+
+const (
+	// Lenient accepts mid-game positions: rows may be any length and cards
+	// missing from the rows are on the foundations, but every card must still
+	// be accounted for exactly once
+	Lenient ParseMode = iota
+	// Strict accepts only a fresh deal: rows and foundations laid out as the
+	// variant deals them (four rows of 7 cards and four of 6 with empty
+	// foundations for Streets and Alleys), holding each card exactly once
+	Strict
+)
+
+// Problem is one thing wrong with the text of a position. Line and Column are
+// 1-based, and 0 when the problem isn't tied to one place in the text.
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the problem prefixed with its position, e.g. "line 3, col 7: ..."
+func (p Problem) String() string {
+	switch {
+	case p.Column > 0:
+		return fmt.Sprintf("line %d, col %d: %s", p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	default:
+		return p.Message
+	}
+}
+
+// ParseError lists every problem found while parsing a position
+type ParseError struct {
+	Problems []Problem
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].String()
+	}
+
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.String()
+	}
+	return fmt.Sprintf("%d problems: %s", len(e.Problems), strings.Join(messages, "; "))
+}
+
+// problemList collects problems as parsing goes along
+type problemList []Problem
+
+func (l *problemList) add(line, column int, format string, args ...any) {
+	*l = append(*l, Problem{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// ParseWithMode returns the game state described by s, checked according to mode
+func ParseWithMode(s string, mode ParseMode) (StreetsGame, error) {
+	var g StreetsGame
+	err := g.FromStringMode(s, mode)
+	return g, err
+}
+
+// FromString reconstructs a game state from its string representation,
+// accepting mid-game positions (see FromStringMode with Lenient)
+// Without a "foundations:" line, any cards missing from the rows are taken
+// to be on the foundations
+func (g *StreetsGame) FromString(s string) error {
+	return g.FromStringMode(s, Lenient)
+}
+
+// token is a whitespace separated word and its 1-based column in the line
+type token struct {
+	text   string
+	column int
+}
+
+// splitTokens splits line into words, remembering where each one starts;
+// offset is the number of characters that came before line
+func splitTokens(line string, offset int) []token {
+	var tokens []token
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		isSpace := i == len(line) || line[i] == ' ' || line[i] == '\t'
+		switch {
+		case !isSpace && start == -1:
+			start = i
+		case isSpace && start != -1:
+			tokens = append(tokens, token{text: line[start:i], column: offset + start + 1})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// EmptyRow stands for a row without cards, so a position never needs a blank
+// line, which would split it in two in a file of deals
+const EmptyRow = "-"
+
+// FromStringMode reconstructs a game state from its string representation,
+// one line of cards per row (EmptyRow or a blank line for an empty one),
+// plus optional "foundations:", "base:" and "variant:" lines; without a
+// "variant:" line the game keeps its variant. A "deal:" line naming the deal
+// number the layout came from is allowed too. Rather than stopping at the
+// first mistake it carries on, and the returned *ParseError lists every
+// malformed token, duplicate or missing card, and (in Strict mode) row of the
+// wrong length.
+func (g *StreetsGame) FromStringMode(s string, mode ParseMode) error {
+	// Clear current state
+	g.Rows = [MaxRows][19]Card{}
+	g.Foundations = [4]int{}
+	g.Base = 0
+
+	var problems problemList
+
+	// Where each card was first seen, so duplicates can point back to it
+	type location struct{ line, column int }
+	seen := make(map[Card]location)
+
+	var rowLines, rowTokens [MaxRows]int // Line number and token count of each row
+	rows := 0
+	hasFoundations, hasVariant, hasDeal := false, false, false
+	foundationsLine, baseLine := 0, 0
+
+	// Handle both Unix and Windows line endings
+	for lineIndex, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		lineNum := lineIndex + 1
+		if tops, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "foundations:"); ok {
+			if hasFoundations {
+				problems.add(lineNum, 0, "more than one foundations line")
+				continue
+			}
+			hasFoundations = true
+			foundationsLine = lineNum
+			g.parseFoundations(splitTokens(tops, len(line)-len(tops)), lineNum, &problems)
+			continue
+		}
+		if rank, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "base:"); ok {
+			if baseLine != 0 {
+				problems.add(lineNum, 0, "more than one base line")
+				continue
+			}
+			baseLine = lineNum
+			g.parseBase(splitTokens(rank, len(line)-len(rank)), lineNum, &problems)
+			continue
+		}
+		if name, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "variant:"); ok {
+			if hasVariant {
+				problems.add(lineNum, 0, "more than one variant line")
+				continue
+			}
+			hasVariant = true
+			g.parseVariant(splitTokens(name, len(line)-len(name)), lineNum, &problems)
+			continue
+		}
+
+		if number, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "deal:"); ok {
+			if hasDeal {
+				problems.add(lineNum, 0, "more than one deal line")
+				continue
+			}
+			hasDeal = true
+			parseDealNumber(splitTokens(number, len(line)-len(number)), lineNum, &problems)
+			continue
+		}
+
+		tokens := splitTokens(line, 0)
+		if len(tokens) == 1 && tokens[0].text == EmptyRow {
+			tokens = nil
+		}
+		if rows == len(g.Rows) {
+			if len(tokens) > 0 {
+				problems.add(lineNum, 0, "too many rows, a game has at most %d", len(g.Rows))
+			}
+			continue
+		}
+		rowLines[rows] = lineNum
+		rowTokens[rows] = len(tokens)
+
+		col := 0
+		for _, tok := range tokens {
+			if col == len(g.Rows[rows]) {
+				problems.add(lineNum, tok.column, "too many cards in row, a row holds at most %d", len(g.Rows[rows]))
+				break
+			}
+
+			card, err := ParseCard(tok.text)
+			if err != nil {
+				problems.add(lineNum, tok.column, "%v", err)
+				continue
+			}
+			if first, ok := seen[card]; ok {
+				problems.add(lineNum, tok.column, "duplicate card %s, first seen at line %d, col %d", card, first.line, first.column)
+				continue
+			}
+			seen[card] = location{lineNum, tok.column}
+
+			g.Rows[rows][col] = card
+			col++
+		}
+		rows++
+	}
+
+	// The variant may only be known now, so check the rows and base against it
+	for rows > g.RowCount() && rowTokens[rows-1] == 0 {
+		rows-- // Trailing blank lines
+	}
+	if rows > g.RowCount() {
+		problems.add(rowLines[g.RowCount()], 0, "too many rows, a %s game has %d", g.Variant, g.RowCount())
+	}
+	if baseLine != 0 && g.Rules().Build().Base != 0 {
+		problems.add(baseLine, 0, "base: %s foundations always start from %s", g.Variant, RankString(g.Rules().Build().Base))
+		g.Base = 0
+	}
+	if g.foundationBase() == 0 && g.Foundations != [4]int{} {
+		problems.add(foundationsLine, 0, "foundations: a base line must say which rank the %s foundations start from", g.Variant)
+	}
+
+	switch {
+	case mode == Strict:
+		g.checkDealLayout(rows, rowLines, rowTokens, hasFoundations, &problems)
+	case !hasFoundations:
+		g.inferFoundations()
+	}
+
+	// Cards listed in the rows can't also be on the foundations
+	for card, at := range seen {
+		if g.onFoundation(card) {
+			problems.add(at.line, at.column, "%s is in a row but already on the foundation", card)
+		}
+	}
+
+	var missing []string
+	for _, suit := range suits {
+		for value := 1; value <= 13; value++ {
+			card := Card{Value: value, Suit: suit}
+			if _, ok := seen[card]; !ok && !g.onFoundation(card) {
+				missing = append(missing, card.String())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		problems.add(0, 0, "missing cards: %s", strings.Join(missing, " "))
+	}
+
+	if len(problems) > 0 {
+		sortProblems(problems)
+		return &ParseError{Problems: problems}
+	}
+	return nil
+}
+
+// parseFoundations reads the top cards listed on a "foundations:" line
+func (g *StreetsGame) parseFoundations(tops []token, lineNum int, problems *problemList) {
+	var seen [4]bool
+	for _, tok := range tops {
+		card, err := ParseCard(tok.text)
+		if err != nil {
+			problems.add(lineNum, tok.column, "foundations: %v", err)
+			continue
+		}
+
+		suit := suitIndex(card.Suit)
+		if seen[suit] {
+			problems.add(lineNum, tok.column, "foundations: more than one %s card", card.Suit)
+			continue
+		}
+		seen[suit] = true
+		g.Foundations[suit] = card.Value
+	}
+}
+
+// parseBase reads the rank on a "base:" line
+func (g *StreetsGame) parseBase(rank []token, lineNum int, problems *problemList) {
+	if len(rank) != 1 || len(rank[0].text) != 1 {
+		problems.add(lineNum, 0, "base: expected one rank, such as 5 or K")
+		return
+	}
+
+	value, ok := parseRank(rank[0].text[0])
+	if !ok {
+		problems.add(lineNum, rank[0].column, "base: invalid rank: %s", rank[0].text)
+		return
+	}
+	g.Base = value
+}
+
+// parseVariant reads the variant named on a "variant:" line
+func (g *StreetsGame) parseVariant(name []token, lineNum int, problems *problemList) {
+	if len(name) != 1 {
+		problems.add(lineNum, 0, "variant: expected one of %s", VariantNames())
+		return
+	}
+
+	variant, err := ParseVariant(name[0].text)
+	if err != nil {
+		problems.add(lineNum, name[0].column, "%v", err)
+		return
+	}
+	g.Variant = variant
+}
+
+// parseDealNumber checks the number on a "deal:" line. The layout is given
+// by the rows, so the number itself is only a label.
+func parseDealNumber(number []token, lineNum int, problems *problemList) {
+	if len(number) != 1 {
+		problems.add(lineNum, 0, "deal: expected one deal number")
+		return
+	}
+	if n, err := strconv.ParseInt(number[0].text, 10, 64); err != nil || n < 1 {
+		problems.add(lineNum, number[0].column, "deal: invalid deal number: %s", number[0].text)
+	}
+}
+
+// checkDealLayout reports rows and foundations that don't match the layout
+// the game's variant deals, allowing the rows in any order. Without a
+// "foundations:" line the foundations are taken to be as dealt.
+func (g *StreetsGame) checkDealLayout(rows int, rowLines, rowTokens [MaxRows]int, hasFoundations bool, problems *problemList) {
+	rowLengths, foundations, fixed := g.Rules().DealShape()
+
+	if g.Base != 0 {
+		problems.add(0, 0, "a fresh %s deal has no base rank chosen", g.Variant)
+	}
+
+	if !fixed {
+		// Cards were played off during the deal, so only the row sizes can be checked
+		if !hasFoundations {
+			g.inferFoundations()
+		}
+		if rows != len(rowLengths) {
+			problems.add(0, 0, "%d rows, a %s deal has %d", rows, g.Variant, len(rowLengths))
+		}
+		for row := 0; row < min(rows, len(rowLengths)); row++ {
+			if rowTokens[row] > rowLengths[row] {
+				problems.add(rowLines[row], 0, "row has %d cards, a %s deal has at most %d", rowTokens[row], g.Variant, rowLengths[row])
+			}
+		}
+		return
+	}
+
+	if !hasFoundations {
+		g.Foundations = foundations
+	} else if g.Foundations != foundations {
+		expected := "nothing"
+		if foundations != [4]int{} {
+			expected = formatFoundations(foundations)
+		}
+		problems.add(0, 0, "a fresh %s deal has %s on the foundations", g.Variant, expected)
+	}
+
+	if rows != len(rowLengths) {
+		problems.add(0, 0, "%d rows, a %s deal has %d", rows, g.Variant, len(rowLengths))
+	}
+
+	// Rows may come in any order, so compare how many rows there are of each length
+	want := make(map[int]int)
+	for _, length := range rowLengths {
+		want[length]++
+	}
+	got := make(map[int]int)
+	badLength := false
+	for row := 0; row < rows; row++ {
+		if want[rowTokens[row]] == 0 {
+			problems.add(rowLines[row], 0, "row has %d cards, a %s deal has rows of %s", rowTokens[row], g.Variant, describeLengths(want))
+			badLength = true
+		}
+		got[rowTokens[row]]++
+	}
+	if rows == len(rowLengths) && !badLength {
+		for length, count := range want {
+			if got[length] != count {
+				problems.add(0, 0, "%s, a %s deal has %s", describeCounts(got), g.Variant, describeCounts(want))
+				break
+			}
+		}
+	}
+}
+
+// describeLengths lists row lengths from longest to shortest, e.g. "7 and 6"
+func describeLengths(counts map[int]int) string {
+	var lengths []string
+	for length := 19; length >= 0; length-- {
+		if counts[length] > 0 {
+			lengths = append(lengths, fmt.Sprint(length))
+		}
+	}
+	return strings.Join(lengths, " and ")
+}
+
+// describeCounts says how many rows there are of each length, e.g.
+// "4 rows of 7 and 4 of 6"
+func describeCounts(counts map[int]int) string {
+	var parts []string
+	for length := 19; length >= 0; length-- {
+		if counts[length] > 0 {
+			if len(parts) == 0 {
+				parts = append(parts, fmt.Sprintf("%d rows of %d", counts[length], length))
+			} else {
+				parts = append(parts, fmt.Sprintf("%d of %d", counts[length], length))
+			}
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// sortProblems orders problems by where they occur in the text, with those
+// about the position as a whole last
+func sortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
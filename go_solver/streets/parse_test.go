@@ -0,0 +1,358 @@
+package streets
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// deal1 is Streets and Alleys deal 1
+const deal1 = `KH JH 7S 5D QD KC 6D
+4D QC 5H AC 2D AS TD
+KS TH 9D 9C 5S TS 4H
+QH 3S 7H AD JD KD 8C
+7D 8S AH 3D 6H 4S
+8D 2C QS JC 6S 2S
+3H 7C 6C 3C TC 4C
+9H 2H 8H 9S 5C JS`
+
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		mode ParseMode
+		want []Problem
+	}{
+		{
+			name: "fresh deal",
+			text: deal1,
+			mode: Strict,
+		},
+		{
+			name: "deal number and variant lines",
+			text: "deal: 1\nvariant: streets-and-alleys\n" + deal1,
+			mode: Strict,
+		},
+		{
+			name: "bad tokens",
+			text: strings.Replace(strings.Replace(deal1, "7S", "7X", 1), "QC", "QCC", 1),
+			mode: Strict,
+			want: []Problem{
+				{1, 7, "invalid suit: 7X"},
+				{2, 4, "invalid card format: QCC"},
+				{0, 0, "missing cards: QC 7S"},
+			},
+		},
+		{
+			name: "duplicate card",
+			text: strings.Replace(deal1, "JS", "KH", 1),
+			mode: Strict,
+			want: []Problem{
+				{8, 16, "duplicate card KH, first seen at line 1, col 1"},
+				{0, 0, "missing cards: JS"},
+			},
+		},
+		{
+			name: "row lengths",
+			text: strings.Replace(deal1, " 4S\n", "\n4S ", 1),
+			mode: Strict,
+			want: []Problem{
+				{5, 0, "row has 5 cards, a streets-and-alleys deal has rows of 7 and 6"},
+			},
+		},
+		{
+			name: "rows of the wrong lengths pass when lenient",
+			text: strings.Replace(deal1, " 4S\n", "\n4S ", 1),
+			mode: Lenient,
+		},
+		{
+			name: "too many rows",
+			text: deal1 + "\nAS",
+			mode: Strict,
+			want: []Problem{
+				{9, 0, "too many rows, a streets-and-alleys game has 8"},
+				{9, 0, "row has 1 cards, a streets-and-alleys deal has rows of 7 and 6"},
+				{9, 1, "duplicate card AS, first seen at line 2, col 16"},
+				{0, 0, "9 rows, a streets-and-alleys deal has 8"},
+			},
+		},
+		{
+			name: "repeated header lines",
+			text: "deal: 1\ndeal: 2\nvariant: streets-and-alleys\nvariant: citadel\n" + deal1,
+			mode: Strict,
+			want: []Problem{
+				{2, 0, "more than one deal line"},
+				{4, 0, "more than one variant line"},
+			},
+		},
+		{
+			name: "bad deal number",
+			text: "deal: x\n" + deal1,
+			mode: Strict,
+			want: []Problem{
+				{1, 7, "deal: invalid deal number: x"},
+			},
+		},
+		{
+			name: "card in a row and on the foundations",
+			text: strings.Replace(deal1, "AS ", "", 1) + "\nfoundations: 2S",
+			mode: Lenient,
+			want: []Problem{
+				{6, 16, "2S is in a row but already on the foundation"},
+			},
+		},
+		{
+			name: "foundations on a fresh deal",
+			text: strings.Replace(deal1, "AS ", "", 1) + "\nfoundations: AS",
+			mode: Strict,
+			want: []Problem{
+				{0, 0, "a fresh streets-and-alleys deal has nothing on the foundations"},
+				{0, 0, "3 rows of 7 and 5 of 6, a streets-and-alleys deal has 4 rows of 7 and 4 of 6"},
+			},
+		},
+		{
+			name: "too many citadel rows",
+			text: citadelNineRows(),
+			mode: Strict,
+			want: []Problem{
+				{10, 0, "too many rows, a citadel game has 8"},
+				{0, 0, "9 rows, a citadel deal has 8"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithMode(tt.text, tt.mode)
+			var got []Problem
+			if err != nil {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("error is %T, want *ParseError: %v", err, err)
+				}
+				got = parseErr.Problems
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems:\n%s\nwant:\n%s", formatProblems(got), formatProblems(tt.want))
+			}
+		})
+	}
+}
+
+// citadelNineRows is Citadel deal 1 with the last card moved to a row of its own
+func citadelNineRows() string {
+	g := StreetsGame{Variant: Citadel}
+	g.Deal(1)
+	rows, foundations, _ := strings.Cut(g.ToString(), "\nfoundations:")
+	last := strings.LastIndex(rows, " ")
+	return rows[:last] + "\n" + rows[last+1:] + "\nfoundations:" + foundations
+}
+
+func formatProblems(problems []Problem) string {
+	var lines []string
+	for _, problem := range problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestEmptyRowsRoundTrip(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(1)
+
+	// Clear out the sixth row onto the foundations
+	for g.getRowLength(5) > 0 {
+		card, col := g.LastCard(5)
+		g.Rows[5][col] = Card{}
+		g.Rows[0][g.getRowLength(0)] = card
+	}
+
+	text := g.ToString()
+	if strings.Contains(text, "\n\n") {
+		t.Fatalf("ToString wrote a blank line for an empty row:\n%s", text)
+	}
+
+	parsed, err := ParseWithMode(text, Lenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != g {
+		t.Errorf("parsed\n%s\nwant\n%s", parsed.ToString(), text)
+	}
+}
+
+func TestToStringRoundTrip(t *testing.T) {
+	for _, variant := range variants {
+		for dealNumber := int64(1); dealNumber <= 20; dealNumber++ {
+			g := StreetsGame{Variant: variant}
+			g.Deal(dealNumber)
+
+			// A fresh deal parses strictly, with the deal number logs put in front
+			text := fmt.Sprintf("deal: %d\n%s", dealNumber, g.ToString())
+			parsed, err := ParseWithMode(text, Strict)
+			if err != nil {
+				t.Fatalf("%s deal %d: %v", variant, dealNumber, err)
+			}
+			if parsed != g {
+				t.Fatalf("%s deal %d: parsed\n%s\nwant\n%s", variant, dealNumber, parsed.ToString(), g.ToString())
+			}
+
+			// Positions along a game keep their foundations, base and empty rows
+			for step := 0; step < 40; step++ {
+				moves := g.LegalMoves()
+				if len(moves) == 0 {
+					break
+				}
+				g, err = g.Apply(moves[(step*5+int(dealNumber))%len(moves)])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				parsed, err := ParseWithMode(g.ToString(), Lenient)
+				if err != nil {
+					t.Fatalf("%s deal %d step %d: %v", variant, dealNumber, step, err)
+				}
+				if parsed != g {
+					t.Fatalf("%s deal %d step %d: parsed\n%s\nwant\n%s", variant, dealNumber, step, parsed.ToString(), g.ToString())
+				}
+			}
+		}
+	}
+}
+
+func TestDealIsSeeded(t *testing.T) {
+	for _, variant := range variants {
+		a := StreetsGame{Variant: variant}
+		a.Deal(1234)
+		b := StreetsGame{Variant: variant}
+		b.Deal(1234)
+		if a != b {
+			t.Errorf("%s deal 1234 came out differently twice", variant)
+		}
+
+		b.Deal(1235)
+		if a == b {
+			t.Errorf("%s deals 1234 and 1235 are the same", variant)
+		}
+	}
+}
+
+// withoutCards returns deal1 with the given cards taken out of the rows
+func withoutCards(cards ...string) string {
+	text := deal1
+	for _, card := range cards {
+		text = strings.Replace(strings.Replace(text, card+" ", "", 1), " "+card, "", 1)
+	}
+	return text
+}
+
+func TestParseFoundations(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want [4]int // Foundation tops, in suit order H D C S
+		errs []Problem
+	}{
+		{
+			name: "listed",
+			text: withoutCards("AH", "2H", "3H", "AS") + "\nfoundations: 3H AS",
+			want: [4]int{3, 0, 0, 1},
+		},
+		{
+			name: "inferred from the missing cards",
+			text: withoutCards("AH", "2H", "3H", "AS"),
+			want: [4]int{3, 0, 0, 1},
+		},
+		{
+			name: "gap below a card in a row",
+			text: withoutCards("AH", "3H"),
+			want: [4]int{1, 0, 0, 0},
+			errs: []Problem{{0, 0, "missing cards: 3H"}},
+		},
+		{
+			name: "gap above the foundation",
+			text: withoutCards("AH", "2H", "3H") + "\nfoundations: 2H",
+			want: [4]int{2, 0, 0, 0},
+			errs: []Problem{{0, 0, "missing cards: 3H"}},
+		},
+		{
+			name: "two tops for a suit",
+			text: withoutCards("AH", "2H") + "\nfoundations: AH 2H",
+			want: [4]int{1, 0, 0, 0},
+			errs: []Problem{
+				{9, 17, "foundations: more than one H card"},
+				{0, 0, "missing cards: 2H"},
+			},
+		},
+		{
+			name: "empty foundations line",
+			text: withoutCards("AH") + "\nfoundations:",
+			errs: []Problem{{0, 0, "missing cards: AH"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g StreetsGame
+			err := g.FromStringMode(tt.text, Lenient)
+			var got []Problem
+			if err != nil {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("error is %T, want *ParseError: %v", err, err)
+				}
+				got = parseErr.Problems
+			}
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("problems:\n%s\nwant:\n%s", formatProblems(got), formatProblems(tt.errs))
+			}
+			if g.Foundations != tt.want {
+				t.Errorf("foundations %v, want %v", g.Foundations, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoundationMoves(t *testing.T) {
+	g := StreetsGame{Variant: StreetsAndAlleys}
+	g.Deal(1)
+
+	// Play through deal 1 until cards go to the foundations
+	played := 0
+	for step := 0; step < 200 && played < 3; step++ {
+		moves := g.LegalMoves()
+		if len(moves) == 0 {
+			break
+		}
+		move := moves[step%len(moves)]
+		for _, m := range moves {
+			if m.To == Foundation {
+				move = m
+			}
+		}
+
+		next, err := g.Apply(move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move.To == Foundation {
+			played++
+			card, _ := g.LastCard(move.From)
+			if top := next.Foundations[suitIndex(card.Suit)]; top != card.Value {
+				t.Errorf("playing %s left %d on its foundation", card, top)
+			}
+			if next.Hash() == g.Hash() {
+				t.Errorf("playing %s to the foundation didn't change the hash", card)
+			}
+			if !next.onFoundation(card) || next.CountCardsInRows() != g.CountCardsInRows()-1 {
+				t.Errorf("%s isn't on the foundation after playing it", card)
+			}
+		}
+		g = next
+	}
+	if played == 0 {
+		t.Fatal("no card reached the foundations")
+	}
+}
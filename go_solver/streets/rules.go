@@ -0,0 +1,372 @@
+package streets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variant selects the rules a game is dealt and played by
+type Variant int
+
+const (
+	// StreetsAndAlleys deals all 52 cards into four rows of 7 and four of 6
+	StreetsAndAlleys Variant = iota
+	// BeleagueredCastle starts with the aces on the foundations and deals the
+	// other 48 cards into eight rows of 6
+	BeleagueredCastle
+	// Citadel deals like Beleaguered Castle, but any card that could be played
+	// to its foundation while dealing goes there instead of into its row
+	Citadel
+	// Fortress deals all 52 cards into two rows of 6 and eight of 5, and
+	// builds up or down in suit
+	Fortress
+	// Chessboard deals like Fortress and builds up or down in suit, turning
+	// the corner from king to ace; the first card played to a foundation
+	// sets the rank every foundation starts from
+	Chessboard
+)
+
+// variants lists every variant, in the order their names are shown
+var variants = []Variant{StreetsAndAlleys, BeleagueredCastle, Citadel, Fortress, Chessboard}
+
+// String returns the name used for the variant on the command line and in
+// deal text
+func (v Variant) String() string {
+	switch v {
+	case BeleagueredCastle:
+		return "beleaguered-castle"
+	case Citadel:
+		return "citadel"
+	case Fortress:
+		return "fortress"
+	case Chessboard:
+		return "chessboard"
+	default:
+		return "streets-and-alleys"
+	}
+}
+
+// ParseVariant parses a variant name such as "beleaguered-castle"
+func ParseVariant(s string) (Variant, error) {
+	for _, variant := range variants {
+		if s == variant.String() {
+			return variant, nil
+		}
+	}
+	return StreetsAndAlleys, fmt.Errorf("unknown variant: %s", s)
+}
+
+// VariantNames lists the names ParseVariant accepts, for flag help
+func VariantNames() string {
+	names := make([]string, len(variants))
+	for i, variant := range variants {
+		names[i] = variant.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// BuildDirection says which way cards are built on each other in the rows
+type BuildDirection int
+
+const (
+	// BuildDown places a card on one a rank higher
+	BuildDown BuildDirection = iota
+	// BuildUp places a card on one a rank lower
+	BuildUp
+	// BuildEither allows both
+	BuildEither
+)
+
+// BuildRules say which cards may be placed on which, in the rows and on the
+// foundations. Foundations always build up in suit, one card at a time.
+// Each variant's build rules are fixed in rulesByVariant, so playing under
+// different ones means adding a Variant rather than setting them on a game.
+type BuildRules struct {
+	Direction BuildDirection
+	SameSuit  bool // Cards in the rows must follow suit
+	Wrap      bool // King and ace are next to each other, in the rows and on the foundations
+	Base      int  // Rank every foundation starts from; 0 lets the first card played to one choose it
+}
+
+// canBuildOn reports whether card may be placed on target in a row
+func (b BuildRules) canBuildOn(card, target Card) bool {
+	if b.SameSuit && card.Suit != target.Suit {
+		return false
+	}
+
+	// Values one above and one below the target
+	above, below := target.Value+1, target.Value-1
+	if b.Wrap {
+		above, below = target.Value%13+1, (target.Value+11)%13+1
+	}
+
+	switch b.Direction {
+	case BuildUp:
+		return card.Value == above
+	case BuildEither:
+		return card.Value == above || card.Value == below
+	default:
+		return card.Value == below
+	}
+}
+
+// Rules deals and plays one variant. Every variant moves a single card at a
+// time off the end of a row, so positions are always a StreetsGame and the
+// solvers only need the rules to tell them which moves are allowed.
+type Rules interface {
+	// Deal lays a shuffled deck out into g's rows and foundations
+	Deal(g *StreetsGame, deck []Card)
+	// DealShape returns the row lengths, in any order, and foundations of a
+	// fresh deal. When fixed is false they depend on the shuffle, and
+	// rowLengths gives the most cards each row can be dealt.
+	DealShape() (rowLengths []int, foundations [4]int, fixed bool)
+	// RowCount returns how many rows the variant plays with
+	RowCount() int
+	// Build returns the rules for placing cards
+	Build() BuildRules
+	// LegalMoves returns all legal moves in g
+	LegalMoves(g *StreetsGame) []Move
+	// Apply returns the game state that results from playing move in g
+	Apply(g *StreetsGame, move Move) (StreetsGame, error)
+}
+
+var (
+	// streetsBuild builds down regardless of suit, with foundations from the ace
+	streetsBuild = BuildRules{Direction: BuildDown, Base: 1}
+
+	// rulesByVariant holds each variant's rules, indexed by Variant
+	rulesByVariant = [...]Rules{
+		StreetsAndAlleys:  streetsRules{play{build: streetsBuild, rows: 8}},
+		BeleagueredCastle: beleagueredRules{play{build: streetsBuild, rows: 8}},
+		Citadel:           citadelRules{play{build: streetsBuild, rows: 8}},
+		Fortress:          fortressRules{play{build: BuildRules{Direction: BuildEither, SameSuit: true, Base: 1}, rows: 10}},
+		Chessboard:        fortressRules{play{build: BuildRules{Direction: BuildEither, SameSuit: true, Wrap: true}, rows: 10}},
+	}
+)
+
+// Rules returns the rules for the variant
+func (v Variant) Rules() Rules {
+	if v < 0 || int(v) >= len(rulesByVariant) {
+		return rulesByVariant[StreetsAndAlleys]
+	}
+	return rulesByVariant[v]
+}
+
+// Rules returns the rules g is played by
+func (g *StreetsGame) Rules() Rules {
+	return g.Variant.Rules()
+}
+
+// RowCount returns how many rows g's variant plays with
+func (g *StreetsGame) RowCount() int {
+	return g.Rules().RowCount()
+}
+
+// dealRows deals the deck in order into rows of the given lengths
+func dealRows(g *StreetsGame, deck []Card, rowLengths []int) {
+	cardIndex := 0
+	for row, cardsInRow := range rowLengths {
+		for col := 0; col < cardsInRow; col++ {
+			g.Rows[row][col] = deck[cardIndex]
+			cardIndex++
+		}
+	}
+}
+
+// play moves cards according to a set of build rules; each variant embeds
+// it and adds its own deal
+type play struct {
+	build BuildRules
+	rows  int
+}
+
+func (p play) Build() BuildRules {
+	return p.build
+}
+
+func (p play) RowCount() int {
+	return p.rows
+}
+
+func (p play) LegalMoves(g *StreetsGame) []Move {
+	moves := make([]Move, 0)
+
+	// Find first empty row if any
+	emptyRow := -1
+	for row := 0; row < p.rows; row++ {
+		if _, col := g.LastCard(row); col == -1 {
+			emptyRow = row
+			break
+		}
+	}
+
+	// For each row, get the last card
+	for fromRow := 0; fromRow < p.rows; fromRow++ {
+		card, col := g.LastCard(fromRow)
+		if col == -1 { // Empty row
+			continue
+		}
+
+		// Check if this card is next on its foundation
+		if g.canPlayToFoundation(card) {
+			moves = append(moves, Move{From: fromRow, To: Foundation})
+		}
+
+		// If we found an empty row, we can move there
+		if emptyRow != -1 && emptyRow != fromRow {
+			moves = append(moves, Move{From: fromRow, To: emptyRow})
+		}
+
+		// Check if this card can move to another non-empty row
+		for toRow := 0; toRow < p.rows; toRow++ {
+			if fromRow == toRow {
+				continue
+			}
+
+			targetCard, targetCol := g.LastCard(toRow)
+			if targetCol == -1 { // Skip empty rows
+				continue
+			}
+
+			if p.build.canBuildOn(card, targetCard) {
+				moves = append(moves, Move{From: fromRow, To: toRow})
+			}
+		}
+	}
+
+	return moves
+}
+
+func (p play) Apply(g *StreetsGame, move Move) (StreetsGame, error) {
+	// Create a copy of the game state
+	newState := g.Clone()
+
+	if move.From < 0 || move.From >= p.rows {
+		return newState, fmt.Errorf("invalid move: no row %d", move.From)
+	}
+
+	// Get the card we're moving
+	card, fromCol := newState.LastCard(move.From)
+	if fromCol == -1 {
+		return newState, fmt.Errorf("invalid move: source row %d is empty", move.From)
+	}
+
+	// If moving to foundation, the card must be next for its suit
+	if move.To == Foundation {
+		if !newState.canPlayToFoundation(card) {
+			return newState, fmt.Errorf("invalid move: %s is not next on its foundation", card)
+		}
+		newState.Rows[move.From][fromCol] = Card{}
+		newState.playToFoundation(card)
+		return newState, nil
+	}
+
+	if move.To < 0 || move.To >= p.rows {
+		return newState, fmt.Errorf("invalid move: no row %d", move.To)
+	}
+
+	// Remove card from source row
+	newState.Rows[move.From][fromCol] = Card{}
+
+	// Otherwise, add card to destination row
+	// Find first empty slot in destination row
+	for col := 0; col < 19; col++ {
+		if (newState.Rows[move.To][col] == Card{}) {
+			newState.Rows[move.To][col] = card
+			return newState, nil
+		}
+	}
+
+	return newState, fmt.Errorf("invalid move: destination row %d is full", move.To)
+}
+
+// streetsRules deal Streets and Alleys: 4 rows of 7 cards and 4 rows of 6
+type streetsRules struct {
+	play
+}
+
+func (streetsRules) Deal(g *StreetsGame, deck []Card) {
+	rowLengths, _, _ := streetsRules{}.DealShape()
+	dealRows(g, deck, rowLengths)
+}
+
+func (streetsRules) DealShape() ([]int, [4]int, bool) {
+	return []int{7, 7, 7, 7, 6, 6, 6, 6}, [4]int{}, true
+}
+
+// beleagueredRules play like Streets and Alleys once the aces are up
+type beleagueredRules struct {
+	play
+}
+
+// Deal puts the aces on the foundations and deals the other cards, in deck
+// order, into 8 rows of 6
+func (beleagueredRules) Deal(g *StreetsGame, deck []Card) {
+	cardIndex := 0
+	for _, card := range deck {
+		if card.Value == 1 {
+			g.Foundations[suitIndex(card.Suit)] = 1
+			continue
+		}
+		g.Rows[cardIndex/6][cardIndex%6] = card
+		cardIndex++
+	}
+}
+
+func (beleagueredRules) DealShape() ([]int, [4]int, bool) {
+	return []int{6, 6, 6, 6, 6, 6, 6, 6}, [4]int{1, 1, 1, 1}, true
+}
+
+// citadelRules play like Beleaguered Castle from the end of the deal
+type citadelRules struct {
+	play
+}
+
+// Deal puts the aces on the foundations, then deals the other cards into
+// the same slots Beleaguered Castle would. A card that is next on its
+// foundation when it is dealt is played there and its slot stays empty, so
+// the layout is the Beleaguered Castle deal of the same shuffle with those
+// cards taken out.
+func (citadelRules) Deal(g *StreetsGame, deck []Card) {
+	for _, card := range deck {
+		if card.Value == 1 {
+			g.Foundations[suitIndex(card.Suit)] = 1
+		}
+	}
+
+	var lengths [MaxRows]int
+	slot := 0
+	for _, card := range deck {
+		if card.Value == 1 {
+			continue
+		}
+
+		row := slot / 6
+		slot++
+		if g.canPlayToFoundation(card) {
+			g.playToFoundation(card)
+			continue
+		}
+		g.Rows[row][lengths[row]] = card
+		lengths[row]++
+	}
+}
+
+func (citadelRules) DealShape() ([]int, [4]int, bool) {
+	return []int{6, 6, 6, 6, 6, 6, 6, 6}, [4]int{1, 1, 1, 1}, false
+}
+
+// fortressRules deal Fortress and Chessboard: all 52 cards into two rows of
+// 6 and eight of 5
+type fortressRules struct {
+	play
+}
+
+func (fortressRules) Deal(g *StreetsGame, deck []Card) {
+	rowLengths, _, _ := fortressRules{}.DealShape()
+	dealRows(g, deck, rowLengths)
+}
+
+func (fortressRules) DealShape() ([]int, [4]int, bool) {
+	return []int{6, 6, 5, 5, 5, 5, 5, 5, 5, 5}, [4]int{}, true
+}
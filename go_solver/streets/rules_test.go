@@ -0,0 +1,148 @@
+package streets
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCanBuildOn(t *testing.T) {
+	tests := []struct {
+		variant Variant
+		card    Card
+		target  Card
+		want    bool
+	}{
+		{StreetsAndAlleys, Card{5, "H"}, Card{6, "S"}, true},
+		{StreetsAndAlleys, Card{5, "H"}, Card{6, "H"}, true},
+		{StreetsAndAlleys, Card{7, "H"}, Card{6, "S"}, false},
+		{StreetsAndAlleys, Card{13, "H"}, Card{1, "S"}, false},
+		{BeleagueredCastle, Card{5, "D"}, Card{6, "C"}, true},
+		{BeleagueredCastle, Card{4, "D"}, Card{6, "C"}, false},
+		{Citadel, Card{12, "S"}, Card{13, "D"}, true},
+		{Citadel, Card{13, "S"}, Card{12, "D"}, false},
+		{Fortress, Card{5, "H"}, Card{6, "H"}, true},
+		{Fortress, Card{7, "H"}, Card{6, "H"}, true},
+		{Fortress, Card{5, "H"}, Card{6, "S"}, false},
+		{Fortress, Card{8, "H"}, Card{6, "H"}, false},
+		{Fortress, Card{13, "C"}, Card{1, "C"}, false},
+		{Fortress, Card{1, "C"}, Card{13, "C"}, false},
+		{Chessboard, Card{5, "H"}, Card{6, "H"}, true},
+		{Chessboard, Card{7, "H"}, Card{6, "H"}, true},
+		{Chessboard, Card{7, "D"}, Card{6, "H"}, false},
+		{Chessboard, Card{13, "C"}, Card{1, "C"}, true},
+		{Chessboard, Card{1, "C"}, Card{13, "C"}, true},
+		{Chessboard, Card{12, "C"}, Card{1, "C"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.variant.Rules().Build().canBuildOn(tt.card, tt.target); got != tt.want {
+			t.Errorf("%s: %s on %s = %v, want %v", tt.variant, tt.card, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestChessboardFoundations(t *testing.T) {
+	g := StreetsGame{Variant: Chessboard}
+
+	// Before a base is chosen any card may start a foundation
+	for _, card := range []Card{{1, "H"}, {7, "D"}, {13, "S"}} {
+		if !g.canPlayToFoundation(card) {
+			t.Errorf("%s can't choose the base", card)
+		}
+	}
+
+	// The first card played sets the base for every suit
+	g.playToFoundation(Card{7, "H"})
+	if g.Base != 7 {
+		t.Fatalf("base %d after playing 7H, want 7", g.Base)
+	}
+	tests := []struct {
+		name        string
+		foundations [4]int
+		card        Card
+		want        bool
+	}{
+		{"next in suit", [4]int{7, 0, 0, 0}, Card{8, "H"}, true},
+		{"below the base", [4]int{7, 0, 0, 0}, Card{6, "H"}, false},
+		{"another suit at the base", [4]int{7, 0, 0, 0}, Card{7, "D"}, true},
+		{"another suit off the base", [4]int{7, 0, 0, 0}, Card{1, "D"}, false},
+		{"ace after the king", [4]int{13, 0, 0, 0}, Card{1, "H"}, true},
+		{"two after the ace", [4]int{1, 0, 0, 0}, Card{2, "H"}, true},
+		{"complete up to the six", [4]int{6, 0, 0, 0}, Card{7, "H"}, false},
+	}
+	for _, tt := range tests {
+		g.Foundations = tt.foundations
+		if got := g.canPlayToFoundation(tt.card); got != tt.want {
+			t.Errorf("%s: %s playable = %v, want %v", tt.name, tt.card, got, tt.want)
+		}
+	}
+
+	// A foundation that has turned the corner counts the cards past the king
+	g.Foundations = [4]int{2, 0, 0, 0}
+	if count := g.foundationCount(0); count != 9 {
+		t.Errorf("7H to 2H is %d cards, want 9", count)
+	}
+	if !g.onFoundation(Card{13, "H"}) || g.onFoundation(Card{3, "H"}) {
+		t.Error("wrapped foundation reports the wrong cards as up")
+	}
+	if d := g.FoundationDistance(Card{6, "H"}); d != 3 {
+		t.Errorf("6H is %d cards away, want 3", d)
+	}
+}
+
+func TestDealLayout(t *testing.T) {
+	for _, variant := range variants {
+		rowLengths, foundations, fixed := variant.Rules().DealShape()
+		for dealNumber := int64(1); dealNumber <= 20; dealNumber++ {
+			g := StreetsGame{Variant: variant}
+			g.Deal(dealNumber)
+
+			var lengths []int
+			for row := 0; row < MaxRows; row++ {
+				if _, col := g.LastCard(row); row >= g.RowCount() && col != -1 {
+					t.Fatalf("%s deal %d: cards in row %d past the last row", variant, dealNumber, row)
+				}
+				if row < g.RowCount() {
+					lengths = append(lengths, g.getRowLength(row))
+				}
+			}
+
+			// Row lengths match DealShape in any order, or are at most as long
+			// when the deal depends on the shuffle
+			sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+			want := append([]int(nil), rowLengths...)
+			sort.Sort(sort.Reverse(sort.IntSlice(want)))
+			for i := range want {
+				if lengths[i] > want[i] || (fixed && lengths[i] != want[i]) {
+					t.Fatalf("%s deal %d: rows of %v, want %v", variant, dealNumber, lengths, want)
+				}
+			}
+
+			up := 0
+			for suit := range g.Foundations {
+				up += g.foundationCount(suit)
+				if g.Foundations[suit] < foundations[suit] || (fixed && g.Foundations[suit] != foundations[suit]) {
+					t.Fatalf("%s deal %d: foundations %v, want %v", variant, dealNumber, g.Foundations, foundations)
+				}
+			}
+			if cards := g.CountCardsInRows(); cards+up != 52 {
+				t.Fatalf("%s deal %d: %d cards in rows and %d up", variant, dealNumber, cards, up)
+			}
+		}
+	}
+}
+
+func TestFortressDealShape(t *testing.T) {
+	g := StreetsGame{Variant: Fortress}
+	g.Deal(1)
+
+	want := []int{6, 6, 5, 5, 5, 5, 5, 5, 5, 5}
+	for row, length := range want {
+		if got := g.getRowLength(row); got != length {
+			t.Errorf("row %d has %d cards, want %d", row, got, length)
+		}
+	}
+	if g.Foundations != [4]int{} || g.Base != 0 {
+		t.Errorf("fresh Fortress deal has foundations %v and base %d", g.Foundations, g.Base)
+	}
+}
@@ -56,6 +56,20 @@ export const streets = $state({
   foundations: [],
 });
 
+// local Go solver, started with `go run ./cmd/solver serve` in go_solver
+const solverUrl = "http://localhost:8080";
+
+export async function canWin() {
+  const response = await fetch(`${solverUrl}/solve`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ rows: streets.rows }),
+  });
+  const result = await response.json();
+  if (!response.ok) throw new Error(result.error);
+  return result;
+}
+
 export function reset() {
   const deck = shuffle(cardDeck);
   streets.rows = dealStreetsAlleys(deck);
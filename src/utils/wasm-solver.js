@@ -0,0 +1,46 @@
+// Loads the Go solver compiled to WebAssembly (npm run build:wasm) and
+// returns its goSolver object. Every function takes and returns JSON strings.
+let solver;
+
+const loadScript = (src) =>
+  new Promise((resolve, reject) => {
+    const script = document.createElement("script");
+    script.src = src;
+    script.onload = resolve;
+    script.onerror = reject;
+    document.head.appendChild(script);
+  });
+
+export const loadSolver = async () => {
+  if (solver) return solver;
+
+  await loadScript("/wasm_exec.js");
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(
+    fetch("/solver.wasm"),
+    go.importObject
+  );
+  go.run(instance);
+  solver = globalThis.goSolver;
+  return solver;
+};
+
+const call = (fn, ...args) => {
+  const result = JSON.parse(fn(...args));
+  if (result && result.error) throw new Error(result.error);
+  return result;
+};
+
+export const parse = async (deal) => call((await loadSolver()).parse, deal);
+
+export const legalMoves = async (rows) =>
+  call((await loadSolver()).legalMoves, JSON.stringify({ rows }));
+
+export const applyMove = async (rows, from, to) =>
+  call((await loadSolver()).apply, JSON.stringify({ rows }), from, to);
+
+export const hint = async (rows) =>
+  call((await loadSolver()).hint, JSON.stringify({ rows }));
+
+export const solve = async (rows) =>
+  call((await loadSolver()).solve, JSON.stringify({ rows }));